@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/srz-zumix/gh-merge-base-next/pkg/mergebasenext"
+)
+
+type BatchOptions struct {
+	Concurrency int
+	InputFormat string
+}
+
+// batchOutput is one line of the batch output, with the result in the JSON output schema.
+type batchOutput struct {
+	Base   string                `json:"base"`
+	Head   string                `json:"head"`
+	Result *mergebasenext.Result `json:"result,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
+
+func NewBatchCmd() *cobra.Command {
+	var batchOpts BatchOptions
+	cmd := &cobra.Command{
+		Use:   "batch [<file>]",
+		Short: "Find the next commit for many base/head pairs in one run",
+		Long: `Find the next commit for many base/head pairs in one run.
+
+Pairs are read from <file>, or from standard input when <file> is omitted or "-".
+The input is CSV ("base,head" per line), JSON (an array of {"base","head"} objects or one object per line), or YAML (a sequence of base/head mappings).
+Pairs are compared concurrently with one client, and one JSON result per pair is written in input order, including per-pair errors.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("pr") {
+				return errors.New("cannot use --pr with batch: base and head are read from the pairs")
+			}
+			input := "-"
+			if len(args) > 0 {
+				input = args[0]
+			}
+			cmd.SilenceUsage = true
+			return RunBatch(cmd, input, &batchOpts)
+		},
+	}
+	f := cmd.Flags()
+	f.IntVarP(&batchOpts.Concurrency, "concurrency", "j", 4, "Number of pairs compared at the same time")
+	f.StringVar(&batchOpts.InputFormat, "input-format", mergebasenext.PairFormatAuto, fmt.Sprintf("Format of the pair input: {%s}", strings.Join(mergebasenext.PairFormats, "|")))
+	return cmd
+}
+
+func RunBatch(cmd *cobra.Command, input string, batchOpts *BatchOptions) error {
+	pairs, err := readPairs(cmd, input, batchOpts.InputFormat)
+	if err != nil {
+		return fmt.Errorf("failed to read pairs from %s: %w", input, err)
+	}
+	if opts.WalkTo == "base" {
+		for i := range pairs {
+			pairs[i].Base, pairs[i].Head = pairs[i].Head, pairs[i].Base
+		}
+	}
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	results := client.GetMergeBaseNextBatch(pairs, opts.Steps, batchOpts.Concurrency)
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+		output := batchOutput{
+			Base:  result.Base,
+			Head:  result.Head,
+			Error: result.Error,
+		}
+		if result.Result != nil {
+			output.Result = mergebasenext.NewResult(result.Result)
+		}
+		if err := encoder.Encode(output); err != nil {
+			return fmt.Errorf("failed to write result for %s...%s: %w", result.Base, result.Head, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pairs failed", failed, len(results))
+	}
+	return nil
+}
+
+func readPairs(cmd *cobra.Command, input string, format string) ([]mergebasenext.Pair, error) {
+	if input == "-" {
+		return mergebasenext.ParsePairs(cmd.InOrStdin(), format)
+	}
+	if format == mergebasenext.PairFormatAuto {
+		format = pairFormatFromExt(input)
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return mergebasenext.ParsePairs(file, format)
+}
+
+func pairFormatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return mergebasenext.PairFormatCSV
+	case ".json", ".jsonl", ".ndjson":
+		return mergebasenext.PairFormatJSON
+	case ".yaml", ".yml":
+		return mergebasenext.PairFormatYAML
+	default:
+		return mergebasenext.PairFormatAuto
+	}
+}
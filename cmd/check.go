@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/spf13/cobra"
+	"github.com/srz-zumix/gh-merge-base-next/pkg/mergebasenext"
+	"github.com/srz-zumix/go-gh-extension/pkg/render"
+)
+
+type CheckOptions struct {
+	Exporter cmdutil.Exporter
+}
+
+func NewCheckCmd() *cobra.Command {
+	var checkOpts CheckOptions
+	cmd := &cobra.Command{
+		Use:   "check {<base> <head> | --pr <number>}",
+		Short: "Check whether merging the next commit into base would conflict",
+		Long: `Check whether merging the next commit into base would conflict, without changing any branch.
+
+With the GitHub API the next commit is test-merged into a throwaway branch created at <base>, which is deleted afterwards.
+The merges API does not name conflicting files, so the files changed on both sides since the merge-base are reported.
+With --local or --git-dir the merge is tested with git merge-tree (git 2.38 or later) and the conflicting files are reported.
+The command exits with status 5 when the merge would conflict.`,
+		Args: baseHeadArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			base, head, err := resolveBaseHead(cmd, args)
+			if err != nil {
+				return err
+			}
+			return RunCheck(cmd, base, head, &checkOpts)
+		},
+	}
+	addJSONFlags(cmd, &checkOpts.Exporter, mergebasenext.CheckFields)
+	return cmd
+}
+
+func RunCheck(cmd *cobra.Command, base string, head string, checkOpts *CheckOptions) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	result, err := client.CheckMergeBaseNext(base, head, opts.Steps)
+	if err != nil {
+		return err
+	}
+	if result.Check != nil && result.Check.Conflict {
+		exitCode = ExitCodeConflict
+	}
+
+	renderer := render.NewRenderer(checkOpts.Exporter)
+	if checkOpts.Exporter != nil {
+		output := mergebasenext.NewResult(result)
+		output.SourcePullRequest = sourcePullRequest
+		return renderer.RenderExportedData(output)
+	}
+	if result.Check == nil {
+		cmd.Printf("Nothing to merge into %s: %s\n", base, result.Status)
+		return nil
+	}
+	if !result.Check.Conflict {
+		cmd.Printf("%s merges cleanly into %s\n", result.SHA, base)
+		return nil
+	}
+	cmd.Printf("%s conflicts with %s\n", result.SHA, base)
+	for _, file := range result.Check.Files {
+		cmd.Println(file)
+	}
+	return nil
+}
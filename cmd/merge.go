@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/spf13/cobra"
+	"github.com/srz-zumix/gh-merge-base-next/pkg/mergebasenext"
+	"github.com/srz-zumix/go-gh-extension/pkg/render"
+)
+
+type MergeOptions struct {
+	BranchTemplate  string
+	DryRun          bool
+	Exporter        cmdutil.Exporter
+	MessageTemplate string
+	Method          string
+}
+
+func NewMergeCmd() *cobra.Command {
+	var mergeOpts MergeOptions
+	cmd := &cobra.Command{
+		Use:   "merge {<base> <head> | --pr <number>}",
+		Short: "Merge the next commit into the base branch",
+		Long: `Merge the next commit into the base branch, one commit at a time.
+
+With --method merge the next commit is merged into <base> with the GitHub merges API.
+With --method pull-request a temporary branch is created at the next commit and a pull request into <base> is opened.
+Templates are Go text/template strings with the fields .Base, .Head, .SHA, .ShortSHA, .Subject, .Message, .Author and .Depth.
+The first line of the message is used as the pull request title and the rest as its body.`,
+		Args: baseHeadArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			base, head, err := resolveBaseHead(cmd, args)
+			if err != nil {
+				return err
+			}
+			return RunMerge(cmd, base, head, &mergeOpts)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&mergeOpts.BranchTemplate, "branch-template", mergebasenext.DefaultBranchTemplate, "Template of the temporary branch name for --method pull-request")
+	f.BoolVar(&mergeOpts.DryRun, "dry-run", false, "Show what would be merged without writing to the repository")
+	f.StringVar(&mergeOpts.MessageTemplate, "message-template", mergebasenext.DefaultMergeMessageTemplate, "Template of the merge commit message or pull request title and body")
+	f.StringVar(&mergeOpts.Method, "method", string(mergebasenext.MergeMethodMerge), fmt.Sprintf("How to merge the next commit: {%s}", strings.Join(mergebasenext.MergeMethods, "|")))
+	addJSONFlags(cmd, &mergeOpts.Exporter, mergebasenext.MergeFields)
+	return cmd
+}
+
+func RunMerge(cmd *cobra.Command, base string, head string, mergeOpts *MergeOptions) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	result, err := client.Merge(base, head, mergebasenext.MergeOptions{
+		Method:          mergebasenext.MergeMethod(mergeOpts.Method),
+		Steps:           opts.Steps,
+		DryRun:          mergeOpts.DryRun,
+		MessageTemplate: mergeOpts.MessageTemplate,
+		BranchTemplate:  mergeOpts.BranchTemplate,
+	})
+	if err != nil {
+		return err
+	}
+
+	renderer := render.NewRenderer(mergeOpts.Exporter)
+	if mergeOpts.Exporter != nil {
+		output := mergebasenext.NewMergeOutput(result)
+		output.SourcePullRequest = sourcePullRequest
+		return renderer.RenderExportedData(output)
+	}
+	prefix := ""
+	if result.DryRun {
+		prefix = "(dry-run) "
+	}
+	switch {
+	case result.Next.Commit == nil:
+		cmd.Printf("Nothing to merge into %s: %s\n", result.Base, result.Next.Status)
+	case result.MergeCommit != nil:
+		cmd.Printf("%sMerged %s into %s: %s\n", prefix, result.Next.SHA, result.Base, result.MergeCommit.GetSHA())
+	case result.PullRequest != nil:
+		cmd.Printf("%sOpened pull request for %s into %s: %s\n", prefix, result.Next.SHA, result.Base, result.PullRequest.GetHTMLURL())
+	case result.Method == mergebasenext.MergeMethodPullRequest:
+		cmd.Printf("%sOpen pull request for %s into %s from branch %s\n", prefix, result.Next.SHA, result.Base, result.Branch)
+	case result.DryRun:
+		cmd.Printf("%sMerge %s into %s\n", prefix, result.Next.SHA, result.Base)
+	default:
+		cmd.Printf("Nothing to merge into %s: %s is already merged\n", result.Base, result.Next.SHA)
+	}
+	return nil
+}
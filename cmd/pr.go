@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/spf13/cobra"
+	"github.com/srz-zumix/gh-merge-base-next/pkg/mergebasenext"
+	"github.com/srz-zumix/go-gh-extension/pkg/render"
+)
+
+type PullRequestOptions struct {
+	Assign          bool
+	BranchTemplate  string
+	DryRun          bool
+	Exporter        cmdutil.Exporter
+	MessageTemplate string
+	ReviewerTeam    string
+}
+
+func NewPullRequestCmd() *cobra.Command {
+	var prOpts PullRequestOptions
+	cmd := &cobra.Command{
+		Use:   "pr {<base> <head> | --pr <number>}",
+		Short: "Open a pull request for the next commit and request review from its author",
+		Long: `Open a pull request for the next commit and request review from its author.
+
+A branch is created at the next commit and a pull request into <base> is opened from it.
+Review is requested from the GitHub user who authored the commit, so they can resolve conflicts with their own changes.
+When the author has no GitHub login (or is a bot), review is requested from --reviewer-team instead.`,
+		Args: baseHeadArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			base, head, err := resolveBaseHead(cmd, args)
+			if err != nil {
+				return err
+			}
+			return RunPullRequest(cmd, base, head, &prOpts)
+		},
+	}
+	f := cmd.Flags()
+	f.BoolVar(&prOpts.Assign, "assign", true, "Also assign the commit author to the pull request")
+	f.StringVar(&prOpts.BranchTemplate, "branch-template", mergebasenext.DefaultBranchTemplate, "Template of the branch name created at the next commit")
+	f.BoolVar(&prOpts.DryRun, "dry-run", false, "Show the pull request and reviewers without writing to the repository")
+	f.StringVar(&prOpts.MessageTemplate, "message-template", mergebasenext.DefaultMergeMessageTemplate, "Template of the pull request title (first line) and body")
+	f.StringVar(&prOpts.ReviewerTeam, "reviewer-team", "", "Team slug ('org/team' or 'team') to request review from when the author has no GitHub login")
+	addJSONFlags(cmd, &prOpts.Exporter, mergebasenext.PullRequestFields)
+	return cmd
+}
+
+func RunPullRequest(cmd *cobra.Command, base string, head string, prOpts *PullRequestOptions) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	result, err := client.OpenPullRequest(base, head, mergebasenext.PullRequestOptions{
+		Steps:           opts.Steps,
+		DryRun:          prOpts.DryRun,
+		MessageTemplate: prOpts.MessageTemplate,
+		BranchTemplate:  prOpts.BranchTemplate,
+		FallbackTeam:    prOpts.ReviewerTeam,
+		Assign:          prOpts.Assign,
+	})
+	if err != nil {
+		return err
+	}
+
+	renderer := render.NewRenderer(prOpts.Exporter)
+	if prOpts.Exporter != nil {
+		output := mergebasenext.NewPullRequestOutput(result)
+		output.SourcePullRequest = sourcePullRequest
+		return renderer.RenderExportedData(output)
+	}
+	if result.Next.Commit == nil {
+		cmd.Printf("Nothing to merge into %s: %s\n", result.Base, result.Next.Status)
+		return nil
+	}
+	reviewers := slices.Concat(result.Reviewers, result.TeamReviewers)
+	if len(reviewers) == 0 {
+		reviewers = []string{"nobody"}
+	}
+	if result.DryRun {
+		cmd.Printf("(dry-run) Open pull request for %s into %s from branch %s, reviewers: %s\n", result.Next.SHA, result.Base, result.Branch, strings.Join(reviewers, ", "))
+		return nil
+	}
+	cmd.Printf("Opened pull request for %s into %s: %s, reviewers: %s\n", result.Next.SHA, result.Base, result.PullRequest.GetHTMLURL(), strings.Join(reviewers, ", "))
+	for _, warning := range result.Warnings {
+		cmd.PrintErrf("Warning: %s\n", warning)
+	}
+	return nil
+}
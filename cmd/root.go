@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cli/cli/v2/pkg/cmdutil"
+	"github.com/cli/go-gh/v2/pkg/repository"
 	"github.com/spf13/cobra"
 	"github.com/srz-zumix/gh-merge-base-next/pkg/mergebasenext"
 	"github.com/srz-zumix/gh-merge-base-next/version"
@@ -12,25 +21,84 @@ import (
 )
 
 type Options struct {
-	Exporter cmdutil.Exporter
-	Repo     string
-	WalkTo   string
+	AppID             int64
+	AppInstallationID int64
+	AppPrivateKey     string
+	ByPullRequest     bool
+	Exporter          cmdutil.Exporter
+	ExitStatus        bool
+	GitDir            string
+	GroupByAuthor     bool
+	Hostname          string
+	List              bool
+	Local             bool
+	Mainline          int
+	MaxDepth          int
+	MaxRetries        int
+	MaxRetryWait      time.Duration
+	MergeBaseReport   string
+	PullRequest       string
+	Repo              string
+	ShowMergeBase     bool
+	SkipAuthors       []string
+	SkipCommitters    []string
+	SkipMarked        bool
+	SkipMessage       string
+	SkipPaths         []string
+	Steps             int
+	Token             string
+	WalkTo            string
+	WithFiles         bool
+	WithPullRequest   bool
 }
 
+// Exit codes reported with --exit-status when there is no next commit
+const (
+	ExitCodeIdentical = 2
+	ExitCodeUpToDate  = 3
+	ExitCodeTruncated = 4
+)
+
+// ExitCodeConflict is reported by the check subcommand when merging the next commit would conflict
+const ExitCodeConflict = 5
+
+// Environment variables that configure the GitHub App when the --app-* flags are not set, such as from secrets of a workflow
+const (
+	EnvAppID             = "GH_MERGE_BASE_NEXT_APP_ID"
+	EnvAppInstallationID = "GH_MERGE_BASE_NEXT_APP_INSTALLATION_ID"
+	EnvAppPrivateKey     = "GH_MERGE_BASE_NEXT_APP_PRIVATE_KEY"
+)
+
 var opts Options
+var exitCode int
+
+// sourcePullRequest is the pull request base and head were resolved from with --pr.
+var sourcePullRequest *mergebasenext.SourcePullRequest
 var rootCmd = &cobra.Command{
-	Use:     "gh-merge-base-next <base> <head>",
+	Use:     "gh-merge-base-next {<base> <head> | --pr <number>}",
 	Short:   "A tool to find the next commit in a merge base",
 	Long:    `gh-merge-base-next is a tool to find the next commit in a merge base.`,
 	Version: version.Version,
-	Args:    cobra.ExactArgs(2),
+	Args:    baseHeadArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		base := args[0]
-		head := args[1]
-		if opts.WalkTo == "base" {
-			base, head = head, base
+		base, head, err := resolveBaseHead(cmd, args)
+		if err != nil {
+			return err
+		}
+		if opts.List {
+			return RunMergeBasePath(cmd, base, head)
+		}
+		if opts.GroupByAuthor || opts.ByPullRequest {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			if opts.ByPullRequest {
+				return RunMergeBaseNextGroup(cmd, base, head, client.GetMergeBaseNextByPullRequest)
+			}
+			return RunMergeBaseNextGroup(cmd, base, head, client.GetMergeBaseNextGroup)
 		}
-		err := RunMergeBaseNext(cmd, base, head)
+		err = RunMergeBaseNext(cmd, base, head)
 		return err
 	},
 }
@@ -40,6 +108,9 @@ func Execute() {
 	if err != nil {
 		os.Exit(1)
 	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
 
 func init() {
@@ -47,28 +118,404 @@ func init() {
 		rootCmd.SetErrPrefix(actions.GetErrorPrefix())
 	}
 	f := rootCmd.Flags()
-	f.StringVarP(&opts.Repo, "repo", "R", "", "Target repository in the format 'owner/repo'")
-	f.StringVarP(&opts.WalkTo, "walk-to", "T", "head", "Specifies whether the next commit of a merge base should walk to the base or the head")
-	cmdutil.AddFormatFlags(rootCmd, &opts.Exporter)
+	f.BoolVar(&opts.ByPullRequest, "by-pr", false, "Step by pull request: return the last commit of the pull request the next commit belongs to")
+	f.BoolVar(&opts.ExitStatus, "exit-status", false, "Exit with a non-zero status when there is no next commit: 2 identical, 3 up-to-date, 4 truncated")
+	f.BoolVar(&opts.GroupByAuthor, "group-by-author", false, "Return the furthest commit such that every commit from the next commit up to it has the same author")
+	f.BoolVarP(&opts.List, "list", "l", false, "List every commit on the first-parent path from the merge-base-next commit to head")
+	pf := rootCmd.PersistentFlags()
+	pf.Int64Var(&opts.AppID, "app-id", 0, "Authenticate as a GitHub App with this app ID (or "+EnvAppID+")")
+	pf.Int64Var(&opts.AppInstallationID, "app-installation-id", 0, "Installation ID of the GitHub App to authenticate as (or "+EnvAppInstallationID+")")
+	pf.StringVar(&opts.AppPrivateKey, "app-private-key", "", "Path to the PEM private key of the GitHub App (or its content in "+EnvAppPrivateKey+")")
+	pf.StringVar(&opts.GitDir, "git-dir", "", "Path to a local git repository to read instead of the GitHub API (implies --local)")
+	pf.StringVar(&opts.Hostname, "hostname", "", "The GitHub host of the repository, such as a GitHub Enterprise Server (default: the host of --repo, GH_HOST or github.com)")
+	pf.BoolVar(&opts.Local, "local", false, "Read commits from the local git repository in the current directory instead of the GitHub API")
+	pf.IntVarP(&opts.Mainline, "mainline", "m", 1, "Follow the Nth parent (starting from 1) of merge commits, like git cherry-pick -m")
+	pf.IntVar(&opts.MaxDepth, "max-depth", 0, "Fail when the first-parent path from head is longer than this many commits (0 means no limit)")
+	pf.IntVar(&opts.MaxRetries, "max-retries", mergebasenext.DefaultRetryPolicy.MaxRetries, "Retry GitHub API requests that are rate limited up to this many times (0 disables retrying)")
+	pf.DurationVar(&opts.MaxRetryWait, "max-retry-wait", mergebasenext.DefaultRetryPolicy.MaxWait, "Longest wait before a retry; a rate limit that lifts later fails right away")
+	pf.StringVar(&opts.MergeBaseReport, "merge-base-report", string(mergebasenext.MergeBaseReportGitHub), fmt.Sprintf("Which merge-base to report for criss-cross histories with more than one, without changing the next commit: {%s}", strings.Join(mergebasenext.MergeBaseReports, "|")))
+	pf.StringVar(&opts.PullRequest, "pr", "", "Pull request number or URL to take the base branch and head commit from, instead of <base> <head>")
+	pf.StringVarP(&opts.Repo, "repo", "R", "", "Target repository in the format '[HOST/]owner/repo'")
+	pf.BoolVar(&opts.ShowMergeBase, "show-merge-base", false, "Show the merge-base commit, and every merge-base candidate of criss-cross histories")
+	pf.StringSliceVar(&opts.SkipAuthors, "skip-author", nil, "Skip commits authored by these GitHub logins, names or emails")
+	pf.StringSliceVar(&opts.SkipCommitters, "skip-committer", nil, "Skip commits committed by these GitHub logins, names or emails")
+	pf.BoolVar(&opts.SkipMarked, "skip-marked", false, "Skip commits whose message contains "+mergebasenext.SkipMarker)
+	pf.StringVar(&opts.SkipMessage, "skip-message", "", "Skip commits whose message matches this regular expression")
+	pf.StringSliceVar(&opts.SkipPaths, "skip-path", nil, "Skip commits that only change files matching these glob patterns (a directory matches the files below it)")
+	pf.IntVarP(&opts.Steps, "steps", "n", 1, "Return the commit N steps past the merge-base on the first-parent path, clamped at the head")
+	pf.StringVar(&opts.Token, "token", "", "Authenticate with this token instead of the gh token (GH_TOKEN, GITHUB_TOKEN or gh auth login)")
+	pf.StringVarP(&opts.WalkTo, "walk-to", "T", "head", "Specifies whether the next commit of a merge base should walk to the base or the head")
+	pf.BoolVar(&opts.WithFiles, "with-files", false, "Include the files the next commit changes, with line counts and CODEOWNERS owners")
+	pf.BoolVar(&opts.WithPullRequest, "with-pr", false, "Include the pull request the next commit belongs to (number, title, author, labels)")
+	resultFields := slices.Concat(mergebasenext.ResultFields, mergebasenext.PathFields)
+	slices.Sort(resultFields)
+	addOutputJSONFlags(rootCmd, &opts.Exporter, slices.Compact(resultFields), func() []string {
+		if opts.List {
+			return mergebasenext.PathFields
+		}
+		return mergebasenext.ResultFields
+	})
+	rootCmd.MarkFlagsMutuallyExclusive("list", "group-by-author", "by-pr")
+	rootCmd.MarkFlagsMutuallyExclusive("list", "with-files")
+	rootCmd.MarkFlagsMutuallyExclusive("list", "with-pr")
+	rootCmd.MarkFlagsMutuallyExclusive("pr", "local")
+	rootCmd.MarkFlagsMutuallyExclusive("pr", "git-dir")
+	rootCmd.MarkFlagsMutuallyExclusive("repo", "local")
+	rootCmd.MarkFlagsMutuallyExclusive("repo", "git-dir")
+	rootCmd.MarkFlagsMutuallyExclusive("hostname", "local")
+	rootCmd.MarkFlagsMutuallyExclusive("hostname", "git-dir")
+	rootCmd.MarkFlagsMutuallyExclusive("token", "app-id")
+
+	rootCmd.AddCommand(NewBatchCmd())
+	rootCmd.AddCommand(NewCheckCmd())
+	rootCmd.AddCommand(NewMergeCmd())
+	rootCmd.AddCommand(NewPullRequestCmd())
+}
+
+// addJSONFlags adds the --json, --jq and --template flags for fields of the JSON output schema,
+// and --format json as a shorthand for --json with every field.
+func addJSONFlags(cmd *cobra.Command, exporter *cmdutil.Exporter, fields []string) {
+	addOutputJSONFlags(cmd, exporter, fields, func() []string { return fields })
+}
+
+// addOutputJSONFlags is addJSONFlags for a command whose output depends on its flags:
+// fields are the fields of every output, and outputFields returns those of the output the flags select,
+// which are the only ones --json accepts and --format json outputs.
+func addOutputJSONFlags(cmd *cobra.Command, exporter *cmdutil.Exporter, fields []string, outputFields func() []string) {
+	var format string
+	cmdutil.StringEnumFlag(cmd, &format, "format", "", "", []string{"json"}, "Output JSON with every field")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		output := outputFields()
+		if format != "" {
+			if cmd.Flags().Changed("json") {
+				return errors.New("cannot use `--format` with `--json`")
+			}
+			return cmd.Flags().Set("json", strings.Join(output, ","))
+		}
+		if !cmd.Flags().Changed("json") {
+			return nil
+		}
+		selected, err := cmd.Flags().GetStringSlice("json")
+		if err != nil {
+			return err
+		}
+		for _, field := range selected {
+			if !slices.Contains(output, field) {
+				return fmt.Errorf("unknown JSON field %q, available fields:\n  %s", field, strings.Join(output, "\n  "))
+			}
+		}
+		return nil
+	}
+	cmdutil.AddJSONFlags(cmd, exporter, fields)
+}
+
+// baseHeadArgs accepts <base> <head>, or no arguments with --pr.
+func baseHeadArgs(cmd *cobra.Command, args []string) error {
+	if opts.PullRequest != "" {
+		if len(args) > 0 {
+			return errors.New("cannot use <base> <head> with --pr")
+		}
+		return nil
+	}
+	return cobra.ExactArgs(2)(cmd, args)
+}
+
+// resolveBaseHead returns the base and head to compare: the arguments, or the base branch and head commit of --pr,
+// swapped with --walk-to base.
+func resolveBaseHead(cmd *cobra.Command, args []string) (string, string, error) {
+	var base, head string
+	if opts.PullRequest != "" {
+		number, repo, err := parsePullRequest(opts.PullRequest)
+		if err != nil {
+			return "", "", err
+		}
+		if repo != "" {
+			if opts.Repo != "" {
+				same, err := sameRepository(opts.Repo, repo)
+				if err != nil {
+					return "", "", err
+				}
+				if !same {
+					return "", "", fmt.Errorf("--pr %s is not a pull request of --repo %s", opts.PullRequest, opts.Repo)
+				}
+			}
+			opts.Repo = repo
+		}
+		client, err := newClient(cmd)
+		if err != nil {
+			return "", "", err
+		}
+		base, head, sourcePullRequest, err = client.ResolvePullRequest(number)
+		if err != nil {
+			return "", "", err
+		}
+	} else {
+		base, head = args[0], args[1]
+	}
+	if opts.WalkTo == "base" {
+		base, head = head, base
+	}
+	return base, head, nil
+}
+
+// sameRepository reports whether --repo repo and prRepo, the HOST/OWNER/REPO of a --pr URL, are the same repository.
+// repo is on its own host, or on --hostname or the default host when it names none.
+func sameRepository(repo string, prRepo string) (bool, error) {
+	var target repository.Repository
+	var err error
+	if opts.Hostname != "" {
+		hostname := strings.TrimPrefix(strings.TrimPrefix(opts.Hostname, "https://"), "http://")
+		target, err = repository.ParseWithHost(repo, strings.TrimSuffix(hostname, "/"))
+	} else {
+		target, err = repository.Parse(repo)
+	}
+	if err != nil {
+		return false, fmt.Errorf("invalid --repo %q: %w", repo, err)
+	}
+	pr, err := repository.Parse(prRepo)
+	if err != nil {
+		return false, fmt.Errorf("invalid --pr %q: %w", opts.PullRequest, err)
+	}
+	return strings.EqualFold(target.Host, pr.Host) && strings.EqualFold(target.Owner, pr.Owner) && strings.EqualFold(target.Name, pr.Name), nil
+}
+
+// parsePullRequest parses a pull request number, optionally prefixed with #, or a URL such as https://github.com/OWNER/REPO/pull/NUMBER.
+// For a URL, the repository of the pull request is returned as well, as HOST/OWNER/REPO.
+func parsePullRequest(value string) (int, string, error) {
+	if number, err := strconv.Atoi(strings.TrimPrefix(value, "#")); err == nil && number > 0 {
+		return number, "", nil
+	}
+	if u, err := url.Parse(value); err == nil && u.Host != "" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 4 && parts[2] == "pull" {
+			if number, err := strconv.Atoi(parts[3]); err == nil && number > 0 {
+				return number, u.Host + "/" + parts[0] + "/" + parts[1], nil
+			}
+		}
+	}
+	return 0, "", fmt.Errorf("invalid --pr %q: expected a pull request number or URL", value)
+}
+
+func newClient(cmd *cobra.Command) (*mergebasenext.Client, error) {
+	if opts.Mainline < 1 {
+		return nil, fmt.Errorf("invalid --mainline %d: parent numbers start from 1", opts.Mainline)
+	}
+	if !slices.Contains(mergebasenext.MergeBaseReports, opts.MergeBaseReport) {
+		return nil, fmt.Errorf("invalid --merge-base-report %q: must be one of %s", opts.MergeBaseReport, strings.Join(mergebasenext.MergeBaseReports, ", "))
+	}
+	skipFilter := mergebasenext.SkipFilter{
+		Authors:    opts.SkipAuthors,
+		Committers: opts.SkipCommitters,
+		Paths:      opts.SkipPaths,
+		Marked:     opts.SkipMarked,
+	}
+	if opts.SkipMessage != "" {
+		re, err := regexp.Compile(opts.SkipMessage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --skip-message: %w", err)
+		}
+		skipFilter.Message = re
+	}
+	if err := skipFilter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid --skip-path: %w", err)
+	}
+	clientOpts := []mergebasenext.ClientOption{
+		mergebasenext.WithMaxDepth(opts.MaxDepth),
+		mergebasenext.WithMergeBaseReport(mergebasenext.MergeBaseReport(opts.MergeBaseReport)),
+		mergebasenext.WithParentIndex(opts.Mainline - 1),
+		mergebasenext.WithSkipFilter(skipFilter),
+	}
+	if opts.ShowMergeBase {
+		clientOpts = append(clientOpts, mergebasenext.WithMergeBases())
+	}
+	if opts.WithFiles {
+		clientOpts = append(clientOpts, mergebasenext.WithFiles())
+	}
+	if opts.WithPullRequest {
+		clientOpts = append(clientOpts, mergebasenext.WithPullRequests())
+	}
+	if opts.Local || opts.GitDir != "" {
+		return mergebasenext.NewLocalClient(cmd.Context(), opts.GitDir, clientOpts...)
+	}
+	if opts.MaxRetries < 0 {
+		return nil, fmt.Errorf("invalid --max-retries %d: must not be negative", opts.MaxRetries)
+	}
+	if opts.MaxRetryWait < 0 {
+		return nil, fmt.Errorf("invalid --max-retry-wait %s: must not be negative", opts.MaxRetryWait)
+	}
+	clientOpts = append(clientOpts, mergebasenext.WithRetryPolicy(mergebasenext.RetryPolicy{
+		MaxRetries:     opts.MaxRetries,
+		InitialBackoff: mergebasenext.DefaultRetryPolicy.InitialBackoff,
+		MaxWait:        opts.MaxRetryWait,
+	}))
+	auth, err := newAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth.Token != "" {
+		clientOpts = append(clientOpts, mergebasenext.WithToken(auth.Token))
+	}
+	if auth.AppID != 0 {
+		clientOpts = append(clientOpts, mergebasenext.WithGitHubApp(auth.AppID, auth.InstallationID, auth.PrivateKey))
+	}
+	if opts.Hostname != "" {
+		clientOpts = append(clientOpts, mergebasenext.WithHostname(opts.Hostname))
+	}
+	return mergebasenext.NewClient(cmd.Context(), opts.Repo, clientOpts...)
+}
+
+// newAuth returns the authentication of --token and the --app-* flags.
+// Without --token, the GitHub App settings that are not given by flags are read from the environment.
+func newAuth() (mergebasenext.Auth, error) {
+	auth := mergebasenext.Auth{
+		Token:          opts.Token,
+		AppID:          opts.AppID,
+		InstallationID: opts.AppInstallationID,
+	}
+	if opts.AppPrivateKey != "" {
+		privateKey, err := os.ReadFile(opts.AppPrivateKey)
+		if err != nil {
+			return auth, fmt.Errorf("invalid --app-private-key: %w", err)
+		}
+		auth.PrivateKey = privateKey
+	}
+	if auth.Token == "" {
+		var err error
+		if auth.AppID == 0 {
+			if auth.AppID, err = envInt64(EnvAppID); err != nil {
+				return auth, err
+			}
+		}
+		if auth.InstallationID == 0 {
+			if auth.InstallationID, err = envInt64(EnvAppInstallationID); err != nil {
+				return auth, err
+			}
+		}
+		if len(auth.PrivateKey) == 0 {
+			auth.PrivateKey = []byte(os.Getenv(EnvAppPrivateKey))
+		}
+	}
+	return auth, auth.Validate()
+}
+
+// envInt64 returns the integer value of the environment variable name, or 0 when it is not set.
+func envInt64(name string) (int64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return n, nil
 }
 
 func RunMergeBaseNext(cmd *cobra.Command, base string, head string) error {
-	client, err := mergebasenext.NewClient(cmd.Context(), opts.Repo)
+	client, err := newClient(cmd)
 	if err != nil {
 		return err
 	}
-	result, err := client.GetMergeBaseNext(base, head)
+	result, err := client.GetMergeBaseNext(base, head, opts.Steps)
 	if err != nil {
 		return err
 	}
 
+	if opts.ExitStatus && result.Commit == nil {
+		exitCode = statusExitCode(result.Status)
+	}
+
+	output := mergebasenext.NewResult(result)
+	output.SourcePullRequest = sourcePullRequest
 	renderer := render.NewRenderer(opts.Exporter)
 	if opts.Exporter != nil {
-		return renderer.RenderExportedData(result)
+		return renderer.RenderExportedData(output)
 	}
+	printMergeBases(cmd, output.MergeBaseCommit, output.MergeBases)
 	if result.Commit == nil {
 		return nil
 	}
 	cmd.Println(result.Commit.GetSHA())
 	return nil
 }
+
+func RunMergeBasePath(cmd *cobra.Command, base string, head string) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	result, err := client.GetMergeBasePath(base, head)
+	if err != nil {
+		return err
+	}
+
+	if opts.ExitStatus && len(result.Commits) == 0 {
+		exitCode = statusExitCode(result.Status)
+	}
+
+	output := mergebasenext.NewPathResult(result)
+	output.SourcePullRequest = sourcePullRequest
+	renderer := render.NewRenderer(opts.Exporter)
+	if opts.Exporter != nil {
+		return renderer.RenderExportedData(output)
+	}
+	printMergeBases(cmd, output.MergeBaseCommit, output.MergeBases)
+	for _, commit := range result.Commits {
+		cmd.Println(commit.GetSHA())
+	}
+	return nil
+}
+
+// RunMergeBaseNextGroup prints the run of commits getGroup returns, such as Client.GetMergeBaseNextGroup
+// or Client.GetMergeBaseNextByPullRequest.
+func RunMergeBaseNextGroup(cmd *cobra.Command, base string, head string, getGroup func(base string, head string, steps int) (*mergebasenext.MergeBaseNextGroup, error)) error {
+	result, err := getGroup(base, head, opts.Steps)
+	if err != nil {
+		return err
+	}
+
+	if opts.ExitStatus && result.Commit == nil {
+		exitCode = statusExitCode(result.Status)
+	}
+
+	output := mergebasenext.NewGroupResult(result)
+	output.SourcePullRequest = sourcePullRequest
+	renderer := render.NewRenderer(opts.Exporter)
+	if opts.Exporter != nil {
+		return renderer.RenderExportedData(output)
+	}
+	printMergeBases(cmd, output.MergeBaseCommit, output.MergeBases)
+	if result.Commit == nil {
+		return nil
+	}
+	cmd.Println(result.To)
+	return nil
+}
+
+// printMergeBases prints the merge-base commit with its subject, then the other merge-base candidates, one per line.
+// Nothing is printed unless --show-merge-base requested the merge-bases.
+func printMergeBases(cmd *cobra.Command, mergeBaseCommit *mergebasenext.Commit, mergeBases []string) {
+	if !opts.ShowMergeBase || mergeBaseCommit == nil {
+		return
+	}
+	subject, _, _ := strings.Cut(mergeBaseCommit.Message, "\n")
+	cmd.Printf("merge-base %s %s\n", mergeBaseCommit.SHA, subject)
+	for _, sha := range mergeBases {
+		if sha != mergeBaseCommit.SHA {
+			cmd.Printf("merge-base %s\n", sha)
+		}
+	}
+}
+
+func statusExitCode(status mergebasenext.Status) int {
+	switch status {
+	case mergebasenext.StatusIdentical:
+		return ExitCodeIdentical
+	case mergebasenext.StatusUpToDate:
+		return ExitCodeUpToDate
+	case mergebasenext.StatusTruncated:
+		return ExitCodeTruncated
+	default:
+		return 0
+	}
+}
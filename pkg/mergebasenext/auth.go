@@ -0,0 +1,192 @@
+package mergebasenext
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/google/go-github/v88/github"
+)
+
+// ErrInvalidAuth is returned when the authentication of NewClient is incomplete or conflicting.
+var ErrInvalidAuth = errors.New("invalid authentication")
+
+// installationTokenMargin is how long before it expires an installation token is renewed.
+const installationTokenMargin = 5 * time.Minute
+
+// Auth configures how NewClient authenticates to the GitHub API.
+// The zero value uses the gh token for the host, from GH_TOKEN, GITHUB_TOKEN or gh auth login.
+type Auth struct {
+	// Token is sent as is, such as a personal access token or the GITHUB_TOKEN of a workflow.
+	Token string
+	// AppID, InstallationID and PrivateKey authenticate as an installation of a GitHub App.
+	// Installation tokens are minted with the PEM encoded private key of the app and renewed before they expire.
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte
+}
+
+// isApp reports whether a authenticates as a GitHub App, even with incomplete settings.
+func (a Auth) isApp() bool {
+	return a.AppID != 0 || a.InstallationID != 0 || len(a.PrivateKey) > 0
+}
+
+// Validate reports a token combined with a GitHub App, an incomplete GitHub App and a private key that cannot be parsed.
+func (a Auth) Validate() error {
+	if !a.isApp() {
+		return nil
+	}
+	if a.Token != "" {
+		return fmt.Errorf("%w: a token and a GitHub App cannot be used together", ErrInvalidAuth)
+	}
+	if a.AppID == 0 || a.InstallationID == 0 || len(a.PrivateKey) == 0 {
+		return fmt.Errorf("%w: a GitHub App needs the app ID, the installation ID and the private key", ErrInvalidAuth)
+	}
+	if _, err := parsePrivateKey(a.PrivateKey); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAuth, err)
+	}
+	return nil
+}
+
+// httpClient creates an HTTP client for the API of host authenticated as configured by a.
+// A nil transport means http.DefaultTransport.
+func (a Auth) httpClient(host string, transport http.RoundTripper) (*http.Client, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	if !a.isApp() {
+		return api.NewHTTPClient(api.ClientOptions{Host: host, AuthToken: a.Token, Transport: transport})
+	}
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	key, err := parsePrivateKey(a.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := newGitHubClient(host, &http.Client{Transport: &appTransport{base: transport, appID: a.AppID, key: key}})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: &installationTransport{base: transport, apps: apps.Apps, installationID: a.InstallationID}}, nil
+}
+
+// parsePrivateKey parses the PEM encoded RSA private key of a GitHub App, in PKCS #1 as GitHub generates it or in PKCS #8.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// appTransport authenticates requests as a GitHub App, with a JWT signed by the private key of the app.
+type appTransport struct {
+	base  http.RoundTripper
+	appID int64
+	key   *rsa.PrivateKey
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := appJWT(t.appID, t.key, time.Now())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// appJWT returns the JWT a GitHub App authenticates with at now.
+// It is issued a minute in the past against clock drift, and expires before the 10 minute limit of GitHub.
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	claims, err := json.Marshal(struct {
+		IssuedAt  int64 `json:"iat"`
+		ExpiresAt int64 `json:"exp"`
+		Issuer    int64 `json:"iss"`
+	}{
+		IssuedAt:  now.Add(-time.Minute).Unix(),
+		ExpiresAt: now.Add(9 * time.Minute).Unix(),
+		Issuer:    appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// installationTransport authenticates requests as an installation of a GitHub App.
+// The installation token is created on the first request and renewed when it is about to expire.
+type installationTransport struct {
+	base           http.RoundTripper
+	apps           *github.AppsService
+	installationID int64
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (t *installationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.installationToken(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
+
+func (t *installationTransport) installationToken(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != "" && time.Until(t.expiresAt) > installationTokenMargin {
+		return t.token, nil
+	}
+	token, _, err := t.apps.CreateInstallationToken(ctx, t.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating a token for GitHub App installation %d: %w", t.installationID, err)
+	}
+	t.token = token.GetToken()
+	t.expiresAt = token.GetExpiresAt().Time
+	return t.token, nil
+}
+
+// closeRequestBody closes the body of a request a RoundTripper fails before sending, as http.RoundTripper requires.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
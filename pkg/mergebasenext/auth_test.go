@@ -0,0 +1,163 @@
+package mergebasenext
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/google/go-github/v88/github"
+)
+
+// newTestPrivateKey returns a GitHub App private key and its PEM encoding in PKCS #1, as GitHub generates it
+func newTestPrivateKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// newAuthTestBackend returns a backend for github.com authenticated with auth whose API requests are served by handler
+func newAuthTestBackend(t *testing.T, auth Auth, handler http.Handler) *GitHubBackend {
+	httpClient, err := auth.httpClient("github.com", newTestServerTransport(t, handler))
+	if err != nil {
+		t.Fatalf("Failed to create HTTP client: %v", err)
+	}
+	client, err := newGitHubClient("github.com", httpClient)
+	if err != nil {
+		t.Fatalf("Failed to create GitHub client: %v", err)
+	}
+	return &GitHubBackend{
+		client: client,
+		repo:   repository.Repository{Host: "github.com", Owner: "srz-zumix", Name: "gh-merge-base-next"},
+	}
+}
+
+// TestAuthValidate tests rejecting a token combined with a GitHub App and incomplete GitHub App settings
+func TestAuthValidate(t *testing.T) {
+	_, privateKey := newTestPrivateKey(t)
+	testCases := []struct {
+		Name  string
+		Auth  Auth
+		Valid bool
+	}{
+		{Name: "gh token", Auth: Auth{}, Valid: true},
+		{Name: "token", Auth: Auth{Token: "secret"}, Valid: true},
+		{Name: "app", Auth: Auth{AppID: 1, InstallationID: 2, PrivateKey: privateKey}, Valid: true},
+		{Name: "token and app", Auth: Auth{Token: "secret", AppID: 1, InstallationID: 2, PrivateKey: privateKey}},
+		{Name: "app without installation", Auth: Auth{AppID: 1, PrivateKey: privateKey}},
+		{Name: "app without private key", Auth: Auth{AppID: 1, InstallationID: 2}},
+		{Name: "malformed private key", Auth: Auth{AppID: 1, InstallationID: 2, PrivateKey: []byte("not a key")}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Auth.Validate()
+			if tc.Valid && err != nil {
+				t.Errorf("Expected valid authentication, got %v", err)
+			}
+			if !tc.Valid && !errors.Is(err, ErrInvalidAuth) {
+				t.Errorf("Expected ErrInvalidAuth, got %v", err)
+			}
+		})
+	}
+}
+
+// TestTokenAuth tests that an explicit token is sent instead of the gh token
+func TestTokenAuth(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/srz-zumix/gh-merge-base-next/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token explicit-token" {
+			t.Errorf("Expected the explicit token, got Authorization %q", got)
+		}
+		_, _ = w.Write([]byte("a1"))
+	})
+
+	backend := newAuthTestBackend(t, Auth{Token: "explicit-token"}, mux)
+	sha, err := backend.GetCommitSHA1(context.Background(), "main")
+	if err != nil {
+		t.Fatalf("GetCommitSHA1 failed: %v", err)
+	}
+	if sha != "a1" {
+		t.Errorf("Expected a1, got %s", sha)
+	}
+}
+
+// TestGitHubAppAuth tests minting an installation token with a JWT signed by the app private key, and reusing it until it is about to expire
+func TestGitHubAppAuth(t *testing.T) {
+	key, privateKey := newTestPrivateKey(t)
+	minted := 0
+	expiresIn := time.Hour
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			t.Errorf("Expected a JWT, got Authorization %q", r.Header.Get("Authorization"))
+		}
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			t.Fatalf("Malformed JWT %q", jwt)
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("JWT signature does not verify: %v", err)
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims struct {
+			IssuedAt  int64 `json:"iat"`
+			ExpiresAt int64 `json:"exp"`
+			Issuer    int64 `json:"iss"`
+		}
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			t.Errorf("Malformed JWT claims %s: %v", payload, err)
+		}
+		if claims.Issuer != 7 || claims.ExpiresAt-claims.IssuedAt > 10*60 {
+			t.Errorf("Unexpected JWT claims %s", payload)
+		}
+		minted++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(&github.InstallationToken{
+			Token:     github.Ptr("installation-token"),
+			ExpiresAt: &github.Timestamp{Time: time.Now().Add(expiresIn)},
+		})
+	})
+	mux.HandleFunc("GET /repos/srz-zumix/gh-merge-base-next/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token installation-token" {
+			t.Errorf("Expected the installation token, got Authorization %q", got)
+		}
+		_, _ = w.Write([]byte("a1"))
+	})
+
+	backend := newAuthTestBackend(t, Auth{AppID: 7, InstallationID: 42, PrivateKey: privateKey}, mux)
+	for range 2 {
+		if _, err := backend.GetCommitSHA1(context.Background(), "main"); err != nil {
+			t.Fatalf("GetCommitSHA1 failed: %v", err)
+		}
+	}
+	if minted != 1 {
+		t.Errorf("Expected one installation token for both requests, got %d", minted)
+	}
+
+	expiresIn = time.Minute
+	backend = newAuthTestBackend(t, Auth{AppID: 7, InstallationID: 42, PrivateKey: privateKey}, mux)
+	for range 2 {
+		if _, err := backend.GetCommitSHA1(context.Background(), "main"); err != nil {
+			t.Fatalf("GetCommitSHA1 failed: %v", err)
+		}
+	}
+	if minted != 3 {
+		t.Errorf("Expected a token about to expire to be renewed, got %d tokens", minted)
+	}
+}
@@ -0,0 +1,103 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// ErrNotFound is matched by errors.Is when a ref, commit or comparison does not exist,
+// such as a 404 response of the GitHub API, which GitHubBackend returns as an APIError matching it.
+var ErrNotFound = errors.New("404 Not Found")
+
+// Backend provides the commit data the first-parent walk is computed from.
+type Backend interface {
+	// CompareCommits compares base and head like the GitHub compare API (base...head),
+	// returning every commit reachable from head but not from base.
+	CompareCommits(ctx context.Context, base string, head string) (*github.CommitsComparison, error)
+	// GetCommitSHA1 resolves ref to a commit SHA.
+	GetCommitSHA1(ctx context.Context, ref string) (string, error)
+}
+
+// MergeBackend is a Backend that can also write to the repository to merge the next commit.
+type MergeBackend interface {
+	Backend
+	// Merge merges head into the base branch with message, returning the merge commit.
+	// The merge commit is nil when there was nothing to merge.
+	Merge(ctx context.Context, base string, head string, message string) (*github.RepositoryCommit, error)
+	// CreateBranch creates a branch pointing at sha.
+	CreateBranch(ctx context.Context, branch string, sha string) error
+	// DeleteBranch deletes a branch.
+	DeleteBranch(ctx context.Context, branch string) error
+	// CreatePullRequest opens a pull request.
+	CreatePullRequest(ctx context.Context, pull *github.NewPullRequest) (*github.PullRequest, error)
+	// RequestReviewers requests reviews on pull request number from users and teams.
+	RequestReviewers(ctx context.Context, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error)
+	// AddAssignees assigns users to pull request number.
+	AddAssignees(ctx context.Context, number int, assignees []string) error
+}
+
+// FileLister is a Backend that can list the files a commit changes.
+type FileLister interface {
+	Backend
+	// ListCommitFiles lists the files changed by the commit sha, compared with its first parent.
+	ListCommitFiles(ctx context.Context, sha string) ([]*github.CommitFile, error)
+}
+
+// FileReader is a Backend that can read files of the repository.
+type FileReader interface {
+	Backend
+	// GetFileContent returns the content of the file at path in ref, or nil when the file does not exist.
+	GetFileContent(ctx context.Context, ref string, path string) ([]byte, error)
+}
+
+// PullRequestLister is a Backend that can find the pull requests a commit belongs to.
+type PullRequestLister interface {
+	Backend
+	// ListPullRequestsWithCommit lists the pull requests associated with the commit sha.
+	ListPullRequestsWithCommit(ctx context.Context, sha string) ([]*github.PullRequest, error)
+}
+
+// PullRequestGetter is a Backend that can read a pull request by number.
+type PullRequestGetter interface {
+	Backend
+	// GetPullRequest returns pull request number.
+	GetPullRequest(ctx context.Context, number int) (*github.PullRequest, error)
+}
+
+// ConflictChecker is a Backend that can test whether merging a commit would conflict, without changing any branch.
+type ConflictChecker interface {
+	Backend
+	// CheckMerge reports whether merging head into base would conflict, and which files conflict.
+	CheckMerge(ctx context.Context, base string, head string) (*MergeCheck, error)
+}
+
+// MergeBaseLister is a Backend that can list every best common ancestor of two commits.
+// Criss-cross merges leave more than one, of which the compare API reports only one.
+type MergeBaseLister interface {
+	Backend
+	// ListMergeBases lists the merge-base commits of base and head, none of which is an ancestor of another.
+	// comparison is the comparison of base and head the caller already has, so that the backend need not compare them again.
+	ListMergeBases(ctx context.Context, base string, head string, comparison *github.CommitsComparison) ([]*github.RepositoryCommit, error)
+}
+
+// MergeCheck is the outcome of a test merge.
+type MergeCheck struct {
+	Conflict bool     `json:"conflict"`
+	Files    []string `json:"files"`
+}
+
+// compareStatus returns the compare API status for the given ahead and behind counts.
+func compareStatus(aheadBy int, behindBy int) string {
+	switch {
+	case aheadBy > 0 && behindBy > 0:
+		return "diverged"
+	case aheadBy > 0:
+		return "ahead"
+	case behindBy > 0:
+		return "behind"
+	default:
+		return "identical"
+	}
+}
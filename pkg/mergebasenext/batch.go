@@ -0,0 +1,54 @@
+package mergebasenext
+
+import (
+	"sync"
+)
+
+// Pair is a base and head to find the merge-base-next commit for.
+type Pair struct {
+	Base string `json:"base" yaml:"base"`
+	Head string `json:"head" yaml:"head"`
+}
+
+// BatchResult is the outcome of GetMergeBaseNext for one pair.
+type BatchResult struct {
+	Base   string         `json:"base"`
+	Head   string         `json:"head"`
+	Result *MergeBaseNext `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// GetMergeBaseNextBatch runs GetMergeBaseNext for every pair on a pool of concurrency workers sharing the client.
+// Results are returned in the order of pairs, and a failing pair records its error instead of aborting the batch.
+func (c *Client) GetMergeBaseNextBatch(pairs []Pair, steps int, concurrency int) []BatchResult {
+	results := make([]BatchResult, len(pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(concurrency, 1), len(pairs)) {
+		wg.Go(func() {
+			for i := range jobs {
+				results[i] = c.getBatchResult(pairs[i], steps)
+			}
+		})
+	}
+	for i := range pairs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func (c *Client) getBatchResult(pair Pair, steps int) BatchResult {
+	result := BatchResult{
+		Base: pair.Base,
+		Head: pair.Head,
+	}
+	next, err := c.GetMergeBaseNext(pair.Base, pair.Head, steps)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Result = next
+	return result
+}
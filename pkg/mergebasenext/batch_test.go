@@ -0,0 +1,101 @@
+package mergebasenext
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestParsePairs tests reading base/head pairs in every supported format
+func TestParsePairs(t *testing.T) {
+	expected := []Pair{
+		{Base: "main", Head: "feature"},
+		{Base: "release/1.0", Head: "main"},
+	}
+	testCases := []struct {
+		Name   string
+		Format string
+		Input  string
+	}{
+		{Name: "CSV", Format: PairFormatCSV, Input: "main,feature\nrelease/1.0,main\n"},
+		{Name: "CSVWithHeaderAndComments", Format: PairFormatAuto, Input: "base,head\n# nightly pairs\n\nmain, feature\nrelease/1.0,main\n"},
+		{Name: "JSONArray", Format: PairFormatAuto, Input: `[{"base":"main","head":"feature"},{"base":"release/1.0","head":"main"}]`},
+		{Name: "JSONLines", Format: PairFormatJSON, Input: "{\"base\":\"main\",\"head\":\"feature\"}\n\n{\"base\":\"release/1.0\",\"head\":\"main\"}\n"},
+		{Name: "YAML", Format: PairFormatAuto, Input: "- base: main\n  head: feature\n- base: release/1.0\n  head: main\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			pairs, err := ParsePairs(strings.NewReader(tc.Input), tc.Format)
+			if err != nil {
+				t.Fatalf("ParsePairs failed: %v", err)
+			}
+			if !slices.Equal(pairs, expected) {
+				t.Errorf("Expected pairs %v, got %v", expected, pairs)
+			}
+		})
+	}
+}
+
+// TestParsePairsErrors tests that incomplete or malformed pair input is rejected
+func TestParsePairsErrors(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Format string
+		Input  string
+		Error  string
+	}{
+		{Name: "MissingHead", Format: PairFormatJSON, Input: `[{"base":"main"}]`, Error: "both base and head are required"},
+		{Name: "WrongFieldCount", Format: PairFormatCSV, Input: "main,feature,extra\n", Error: "wrong number of fields"},
+		{Name: "UnknownFormat", Format: "toml", Input: "", Error: "unknown pair format"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := ParsePairs(strings.NewReader(tc.Input), tc.Format)
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tc.Error) {
+				t.Errorf("Expected error to contain '%s', got '%s'", tc.Error, err.Error())
+			}
+		})
+	}
+}
+
+// TestGetMergeBaseNextBatch tests that batch results keep the input order and record per-pair errors
+func TestGetMergeBaseNextBatch(t *testing.T) {
+	client := getTestClient(t)
+	pairs := []Pair{
+		{Base: "testdata/simple-merge/main", Head: "testdata/simple-merge/feature"},
+		{Base: "testdata/error-cases/main", Head: "testdata/error-cases/nonexistent-branch"},
+		{Base: "testdata/fast-forward/main", Head: "testdata/fast-forward/feature"},
+		{Base: "testdata/complex-merge/main", Head: "testdata/complex-merge/feature2"},
+	}
+	results := client.GetMergeBaseNextBatch(pairs, 1, 2)
+	if len(results) != len(pairs) {
+		t.Fatalf("Expected %d results, got %d", len(pairs), len(results))
+	}
+	expectedSHAs := []string{
+		"d761e77fe7bbc5fd65e8ee14b8a65ea2ff1f0043",
+		"",
+		"463ec54c18fd7544fcf276aec8a3c1e185b61b6c",
+		"8bea32a6b9ff20cbfe949e5e8f82ddbf84197ad1",
+	}
+	for i, result := range results {
+		if result.Base != pairs[i].Base || result.Head != pairs[i].Head {
+			t.Errorf("Result %d is for %s...%s, expected %s...%s", i, result.Base, result.Head, pairs[i].Base, pairs[i].Head)
+		}
+		if expectedSHAs[i] == "" {
+			if result.Error == "" || result.Result != nil {
+				t.Errorf("Expected result %d to be an error, got %+v", i, result)
+			}
+			continue
+		}
+		if result.Error != "" {
+			t.Errorf("Unexpected error for result %d: %s", i, result.Error)
+			continue
+		}
+		if result.Result.SHA != expectedSHAs[i] {
+			t.Errorf("Expected result %d SHA %s, got %s", i, expectedSHAs[i], result.Result.SHA)
+		}
+	}
+}
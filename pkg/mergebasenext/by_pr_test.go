@@ -0,0 +1,132 @@
+package mergebasenext
+
+import (
+	"context"
+	"testing"
+)
+
+// byPullRequestGraph has a merged, a squash merged and a rebase merged pull request, then a direct commit
+var byPullRequestGraph = `
+main    -> b1
+feature -> d1
+
+d1 <- r2 @dave            : D1: Direct commit without a pull request
+r2 <- r1 @carol #13       : R2: Rebased change 2
+r1 <- s1 @carol #13       : R1: Rebased change 1
+s1 <- m1 @bob #12         : Squashed change (#12)
+m1 <- a1 p1 @alice #11    : Merge pull request #11 from example/topic
+p1 <- a1 @alice #11       : P1: Work in pull request 11
+b1 <- a1                  : B: Main branch development
+a1                        : A: Initial commit (merge-base)
+`
+
+// TestGetMergeBaseNextByPullRequest tests stepping along the first-parent path one pull request at a time
+func TestGetMergeBaseNextByPullRequest(t *testing.T) {
+	client := newGraphClient(t, byPullRequestGraph)
+	testCases := []struct {
+		Name   string
+		Steps  int
+		From   string
+		To     string
+		Number int
+		Title  string
+		Author string
+		Count  int
+		Depth  int
+	}{
+		{Name: "MergeCommit", Steps: 1, From: "m1", To: "m1", Number: 11, Title: "Merge pull request #11 from example/topic", Author: "alice", Count: 1, Depth: 5},
+		{Name: "SquashMerge", Steps: 2, From: "s1", To: "s1", Number: 12, Title: "Squashed change (#12)", Author: "bob", Count: 1, Depth: 4},
+		{Name: "RebaseMerge", Steps: 3, From: "r1", To: "r2", Number: 13, Title: "R2: Rebased change 2", Author: "carol", Count: 2, Depth: 2},
+		{Name: "WithoutPullRequest", Steps: 4, From: "d1", To: "d1", Author: "dave", Count: 1, Depth: 1},
+		{Name: "ClampedAtHead", Steps: 9, From: "d1", To: "d1", Author: "dave", Count: 1, Depth: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := client.GetMergeBaseNextByPullRequest("main", "feature", tc.Steps)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNextByPullRequest failed: %v", err)
+			}
+			if result.From != tc.From || result.To != tc.To || result.Author != tc.Author || result.Count != tc.Count || result.Depth != tc.Depth {
+				t.Errorf("Expected %s..%s by %s (count %d, depth %d), got %s..%s by %s (count %d, depth %d)",
+					tc.From, tc.To, tc.Author, tc.Count, tc.Depth, result.From, result.To, result.Author, result.Count, result.Depth)
+			}
+			if tc.Number == 0 {
+				if result.PullRequest != nil {
+					t.Errorf("Expected no pull request, got #%d", result.PullRequest.Number)
+				}
+				return
+			}
+			if result.PullRequest == nil {
+				t.Fatalf("Expected pull request #%d", tc.Number)
+			}
+			if result.PullRequest.Number != tc.Number || result.PullRequest.Title != tc.Title {
+				t.Errorf("Expected pull request #%d %q, got #%d %q", tc.Number, tc.Title, result.PullRequest.Number, result.PullRequest.Title)
+			}
+		})
+	}
+}
+
+// TestWithPullRequests tests that the pull request of the next commit is included in the result
+func TestWithPullRequests(t *testing.T) {
+	backend, err := NewMemoryBackend(byPullRequestGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	client := NewClientWithBackend(context.Background(), backend, WithPullRequests())
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.PullRequest == nil || result.PullRequest.Number != 11 || result.PullRequest.Author != "alice" {
+		t.Errorf("Expected pull request #11 by alice, got %+v", result.PullRequest)
+	}
+
+	client = NewClientWithBackend(context.Background(), backend)
+	result, err = client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.PullRequest != nil {
+		t.Errorf("Expected no pull request lookup by default, got %+v", result.PullRequest)
+	}
+}
+
+// TestGitBackendPullRequests tests recognizing pull requests from GitHub merge and squash merge messages
+func TestGitBackendPullRequests(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("A", "A: Initial commit")
+	f.commit("S", "Fix the build (#42)")
+	f.commit("M", "Merge pull request #43 from example/topic\n\nAdd the feature")
+	f.commit("D", "Direct commit")
+
+	backend, err := NewGitBackend(f.dir)
+	if err != nil {
+		t.Fatalf("NewGitBackend failed: %v", err)
+	}
+	testCases := []struct {
+		Ref    string
+		Number int
+		Title  string
+	}{
+		{Ref: "S", Number: 42, Title: "Fix the build"},
+		{Ref: "M", Number: 43, Title: "Add the feature"},
+		{Ref: "D"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Ref, func(t *testing.T) {
+			pulls, err := backend.ListPullRequestsWithCommit(context.Background(), f.sha(tc.Ref))
+			if err != nil {
+				t.Fatalf("ListPullRequestsWithCommit failed: %v", err)
+			}
+			if tc.Number == 0 {
+				if len(pulls) != 0 {
+					t.Errorf("Expected no pull request, got %d", len(pulls))
+				}
+				return
+			}
+			if len(pulls) != 1 || pulls[0].GetNumber() != tc.Number || pulls[0].GetTitle() != tc.Title {
+				t.Errorf("Expected pull request #%d %q, got %v", tc.Number, tc.Title, pulls)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package mergebasenext
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConflictCheckUnsupported is returned when the client backend cannot test merges.
+var ErrConflictCheckUnsupported = errors.New("backend does not support conflict checks")
+
+// CheckMergeBaseNext returns the merge-base-next commit like GetMergeBaseNext, with Check reporting whether
+// merging that commit into base would conflict. Check is nil when there is no next commit.
+func (c *Client) CheckMergeBaseNext(base string, head string, steps int) (*MergeBaseNext, error) {
+	checker, ok := c.backend.(ConflictChecker)
+	if !ok {
+		return nil, ErrConflictCheckUnsupported
+	}
+	result, err := c.GetMergeBaseNext(base, head, steps)
+	if err != nil {
+		return nil, err
+	}
+	if result.Commit == nil {
+		return result, nil
+	}
+	result.Check, err = checker.CheckMerge(c.ctx, base, result.SHA)
+	if err != nil {
+		return nil, fmt.Errorf("error checking merge of %s into %s: %w", result.SHA, base, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package mergebasenext
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// conflictGraph has feature commits that do and do not touch files also changed on main
+var conflictGraph = `
+main    -> b1
+feature -> c2
+
+c2 <- c1 +go.mod +docs/feature.md : C2: Bump dependencies
+c1 <- a1 +docs/feature.md         : C1: Document the feature
+b1 <- a1 +go.mod +README.md       : B: Bump dependencies on main
+a1 +go.mod +README.md             : A: Initial commit (merge-base)
+`
+
+// TestCheckMergeBaseNext tests the conflict pre-check of the next commit against the in-memory backend
+func TestCheckMergeBaseNext(t *testing.T) {
+	client := newGraphClient(t, conflictGraph)
+	testCases := []struct {
+		Name     string
+		Steps    int
+		Conflict bool
+		Files    []string
+	}{
+		{Name: "Clean", Steps: 1, Conflict: false, Files: []string{}},
+		{Name: "Conflict", Steps: 2, Conflict: true, Files: []string{"go.mod"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := client.CheckMergeBaseNext("main", "feature", tc.Steps)
+			if err != nil {
+				t.Fatalf("CheckMergeBaseNext failed: %v", err)
+			}
+			if result.Check == nil {
+				t.Fatalf("Expected a merge check")
+			}
+			if result.Check.Conflict != tc.Conflict || !slices.Equal(result.Check.Files, tc.Files) {
+				t.Errorf("Expected conflict %v with files %v, got %v with files %v", tc.Conflict, tc.Files, result.Check.Conflict, result.Check.Files)
+			}
+		})
+	}
+
+	t.Run("NothingToMerge", func(t *testing.T) {
+		result, err := client.CheckMergeBaseNext("feature", "a1", 1)
+		if err != nil {
+			t.Fatalf("CheckMergeBaseNext failed: %v", err)
+		}
+		if result.Check != nil {
+			t.Errorf("Expected no merge check without a next commit, got %+v", result.Check)
+		}
+	})
+}
+
+// TestGitBackendCheckMerge tests the conflict pre-check with git merge-tree on a generated fixture repository
+func TestGitBackendCheckMerge(t *testing.T) {
+	f := newGitFixture(t)
+	if out, err := exec.Command("git", "merge-tree", "-h").CombinedOutput(); !strings.Contains(string(out), "--write-tree") {
+		t.Skipf("git merge-tree --write-tree is not supported: %v", err)
+	}
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(f.dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		f.git("add", name)
+	}
+	write("go.mod", "module example\n")
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	write("feature.md", "feature\n")
+	f.commit("C1", "C1: Document the feature")
+	write("go.mod", "module example\n\ngo 1.25\n")
+	f.commit("C2", "C2: Bump go")
+	f.git("checkout", "--quiet", "main")
+	write("go.mod", "module example\n\ngo 1.24\n")
+	f.commit("B", "B: Bump go on main")
+
+	client, err := NewLocalClient(t.Context(), f.dir)
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	result, err := client.CheckMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("CheckMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != f.sha("C1") || result.Check.Conflict {
+		t.Errorf("Expected a clean merge of C1, got %s %+v", result.SHA, result.Check)
+	}
+	result, err = client.CheckMergeBaseNext("main", "feature", 2)
+	if err != nil {
+		t.Fatalf("CheckMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != f.sha("C2") || !result.Check.Conflict || !slices.Equal(result.Check.Files, []string{"go.mod"}) {
+		t.Errorf("Expected C2 to conflict in go.mod, got %s %+v", result.SHA, result.Check)
+	}
+	if branch := f.git("rev-parse", "main"); branch != f.sha("B") {
+		t.Errorf("Expected main to be left at B, got %s", branch)
+	}
+}
+
+// TestGitHubBackendCheckMerge tests the test merge on a throwaway branch through the GitHub API
+func TestGitHubBackendCheckMerge(t *testing.T) {
+	for _, conflict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Conflict=%v", conflict), func(t *testing.T) {
+			created, deleted := "", ""
+			mux := http.NewServeMux()
+			repoPath := "/api/v3/repos/srz-zumix/gh-merge-base-next"
+			mux.HandleFunc("GET "+repoPath+"/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, r.PathValue("ref")+"-sha")
+			})
+			mux.HandleFunc("POST "+repoPath+"/git/refs", func(w http.ResponseWriter, r *http.Request) {
+				var ref github.CreateRef
+				if err := json.NewDecoder(r.Body).Decode(&ref); err != nil || ref.SHA != "main-sha" {
+					t.Errorf("Unexpected test merge ref %+v: %v", ref, err)
+				}
+				created = ref.Ref
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, "{}")
+			})
+			mux.HandleFunc("POST "+repoPath+"/merges", func(w http.ResponseWriter, r *http.Request) {
+				if conflict {
+					w.WriteHeader(http.StatusConflict)
+					fmt.Fprint(w, `{"message":"Merge conflict"}`)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"sha":"merged"}`)
+			})
+			mux.HandleFunc("GET "+repoPath+"/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+				files := map[string][]string{
+					"main-sha...next-sha": {"a.go", "b.go"},
+					"next-sha...main-sha": {"b.go", "c.go"},
+				}[r.PathValue("basehead")]
+				comparison := &github.CommitsComparison{}
+				for _, file := range files {
+					comparison.Files = append(comparison.Files, &github.CommitFile{Filename: github.Ptr(file)})
+				}
+				_ = json.NewEncoder(w).Encode(comparison)
+			})
+			mux.HandleFunc("DELETE "+repoPath+"/git/refs/{ref...}", func(w http.ResponseWriter, r *http.Request) {
+				deleted = "refs/" + r.PathValue("ref")
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			backend := newTestServerClient(t, mux).backend.(*GitHubBackend)
+			check, err := backend.CheckMerge(t.Context(), "main", "next")
+			if err != nil {
+				t.Fatalf("CheckMerge failed: %v", err)
+			}
+			expectedFiles := []string{}
+			if conflict {
+				expectedFiles = []string{"b.go"}
+			}
+			if check.Conflict != conflict || !slices.Equal(check.Files, expectedFiles) {
+				t.Errorf("Expected conflict %v with files %v, got %+v", conflict, expectedFiles, check)
+			}
+			if !strings.HasPrefix(created, "refs/heads/"+checkBranchPrefix) || created != deleted {
+				t.Errorf("Expected throwaway branch to be created and deleted, created %q, deleted %q", created, deleted)
+			}
+		})
+	}
+}
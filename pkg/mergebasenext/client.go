@@ -2,33 +2,102 @@ package mergebasenext
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/cli/go-gh/v2/pkg/repository"
-	"github.com/srz-zumix/go-gh-extension/pkg/gh"
 	"github.com/srz-zumix/go-gh-extension/pkg/parser"
 )
 
+// ErrHostMismatch is returned by NewClient when the repository names another host than WithHostname.
+var ErrHostMismatch = errors.New("repository host does not match the hostname")
+
 type Client struct {
-	client *gh.GitHubClient
-	ctx    context.Context
-	repo   repository.Repository
+	auth        Auth
+	backend     Backend
+	ctx         context.Context
+	hostname    string
+	maxDepth    int
+	parentIndex int
+	skipFilter  *SkipFilter
+
+	mergeBaseReport MergeBaseReport
+	retryPolicy     *RetryPolicy
+	transport       http.RoundTripper
+
+	withFiles        bool
+	withMergeBases   bool
+	withPullRequests bool
 }
 
-func NewClient(ctx context.Context, repo string) (*Client, error) {
-	repository, err := parser.Repository(parser.RepositoryInput(repo))
+// NewClient creates a client that reads repo through the GitHub API.
+// repo is OWNER/REPO, HOST/OWNER/REPO or a URL, and an empty repo is the repository of the current directory.
+// It authenticates with the gh token for the host of repo, unless WithToken or WithGitHubApp is given.
+func NewClient(ctx context.Context, repo string, opts ...ClientOption) (*Client, error) {
+	c := NewClientWithBackend(ctx, nil, opts...)
+	target, err := parseRepository(repo, c.hostname)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing repository: %w", err)
+		return nil, err
 	}
 
-	client, err := gh.NewGitHubClientWithRepo(repository)
+	policy := DefaultRetryPolicy
+	if c.retryPolicy != nil {
+		policy = *c.retryPolicy
+	}
+	backend, err := newGitHubBackend(target, c.auth, c.transport, policy)
 	if err != nil {
 		return nil, fmt.Errorf("error creating GitHub client: %w", err)
 	}
+	c.backend = backend
+	return c, nil
+}
+
+// parseRepository parses repo for NewClient, on hostname unless it is empty.
+// A host given by repo itself must be hostname.
+func parseRepository(repo string, hostname string) (repository.Repository, error) {
+	r, err := parser.Repository(parser.RepositoryInput(repo))
+	if err != nil {
+		return r, fmt.Errorf("error parsing repository: %w", err)
+	}
+	if hostname == "" {
+		return r, nil
+	}
+	if repo != "" {
+		if named, err := repository.ParseWithHost(repo, ""); err == nil && named.Host != "" && !strings.EqualFold(named.Host, hostname) {
+			return r, fmt.Errorf("%w: repository %s is on %s, not on %s", ErrHostMismatch, repo, named.Host, hostname)
+		}
+	}
+	r.Host = hostname
+	return r, nil
+}
+
+// normalizeHostname returns the host of hostname, which may be written as a URL.
+func normalizeHostname(hostname string) string {
+	hostname = strings.TrimPrefix(strings.TrimPrefix(hostname, "https://"), "http://")
+	return strings.ToLower(strings.TrimSuffix(hostname, "/"))
+}
+
+// NewLocalClient creates a client that reads the local git repository at dir.
+// An empty dir means the current working directory.
+func NewLocalClient(ctx context.Context, dir string, opts ...ClientOption) (*Client, error) {
+	backend, err := NewGitBackend(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error opening git repository: %w", err)
+	}
+
+	return NewClientWithBackend(ctx, backend, opts...), nil
+}
 
-	return &Client{
-		client: client,
-		ctx:    ctx,
-		repo:   repository,
-	}, nil
+// NewClientWithBackend creates a client that reads commits from backend.
+func NewClientWithBackend(ctx context.Context, backend Backend, opts ...ClientOption) *Client {
+	c := &Client{
+		backend: backend,
+		ctx:     ctx,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
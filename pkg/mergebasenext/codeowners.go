@@ -0,0 +1,85 @@
+package mergebasenext
+
+import (
+	"regexp"
+	"strings"
+)
+
+// codeownersPaths are the locations GitHub reads the CODEOWNERS file from, in order of precedence.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeowners maps files to their owners with the rules of a CODEOWNERS file.
+type codeowners struct {
+	rules []codeownersRule
+}
+
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// parseCodeowners parses a CODEOWNERS file. Lines with invalid patterns are ignored, as GitHub does.
+func parseCodeowners(content []byte) *codeowners {
+	c := &codeowners{}
+	for line := range strings.Lines(string(content)) {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := codeownersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		c.rules = append(c.rules, codeownersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return c
+}
+
+// Owners returns the owners of file given by the last matching rule, or none when no rule matches.
+func (c *codeowners) Owners(file string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(file) {
+			return c.rules[i].owners
+		}
+	}
+	return []string{}
+}
+
+// codeownersPattern converts a gitignore-style CODEOWNERS pattern into a regular expression matching file paths.
+// A pattern with a leading or inner slash is relative to the repository root, otherwise it matches at any depth,
+// and a pattern matching a directory matches every file below it.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if directory {
+		sb.WriteString("/.*$")
+	} else {
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
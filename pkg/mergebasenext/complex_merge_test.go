@@ -33,6 +33,21 @@ import (
 	"testing"
 )
 
+// complexMergeGraph describes the commit graph above for the offline test backend
+var complexMergeGraph = `
+testdata/complex-merge/main     -> b1f8984
+testdata/complex-merge/feature1 -> 75ff397
+testdata/complex-merge/feature2 -> 66610c6
+
+b1f8984e815df71ba66cbbbbe7559365d0c82d4f <- 12898c7         : G: More main development
+12898c7                                  <- 8aa27fa 75ff397 : E: Merge feature1 into main
+75ff397267f653296190ade85624b664920353b4 <- 00506e8         : B: Feature1 development
+8aa27fa091aa909e06c42dd61c83c1395f034fb8 <- 00506e8         : D: Main branch development
+66610c6                                  <- 8bea32a         : F: More feature2 development
+8bea32a6b9ff20cbfe949e5e8f82ddbf84197ad1 <- 00506e8         : C: Feature2 development
+00506e8                                                     : A: Initial commit (merge-base)
+`
+
 // TestComplexMerge tests complex merge scenarios with multiple branches, merge commits, and depth validation
 func TestComplexMerge(t *testing.T) {
 	testCases := []TestCase{
@@ -85,12 +100,13 @@ func TestComplexMerge(t *testing.T) {
 			Desc:  "Find next commit from merge-base (A: Initial commit) to feature1 branch",
 		},
 		{
-			Name:  "AutoDetectMergeBaseMainToFeature1WalkToHead",
-			Base:  "testdata/complex-merge/main",
-			Head:  "testdata/complex-merge/feature1",
-			SHA:   "",
-			Depth: 0,
-			Desc:  "Auto-detect merge-base between main and feature1, walk to feature1 (head)",
+			Name:   "AutoDetectMergeBaseMainToFeature1WalkToHead",
+			Base:   "testdata/complex-merge/main",
+			Head:   "testdata/complex-merge/feature1",
+			SHA:    "",
+			Depth:  0,
+			Status: StatusUpToDate,
+			Desc:   "Auto-detect merge-base between main and feature1, walk to feature1 (head)",
 		},
 		{
 			Name:  "AutoDetectMergeBaseMainToFeature1WalkToBase",
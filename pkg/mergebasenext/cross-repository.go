@@ -0,0 +1,32 @@
+package mergebasenext
+
+import (
+	"strings"
+)
+
+// crossRepositoryRef is a ref of another repository in the same network, such as a fork.
+type crossRepositoryRef struct {
+	owner string
+	// repo is empty when the ref does not name the repository.
+	repo string
+	ref  string
+}
+
+// parseCrossRepositoryRef parses owner:ref, the syntax of the GitHub compare API for refs of another repository in the network,
+// or owner:repo:ref for a repository whose name differs. Git ref names cannot contain a colon, so ok is false for any other ref.
+func parseCrossRepositoryRef(ref string) (crossRepositoryRef, bool) {
+	parts := strings.Split(ref, ":")
+	for _, part := range parts {
+		if part == "" {
+			return crossRepositoryRef{}, false
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return crossRepositoryRef{owner: parts[0], ref: parts[1]}, true
+	case 3:
+		return crossRepositoryRef{owner: parts[0], repo: parts[1], ref: parts[2]}, true
+	default:
+		return crossRepositoryRef{}, false
+	}
+}
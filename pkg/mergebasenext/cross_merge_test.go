@@ -33,6 +33,21 @@ import (
 	"testing"
 )
 
+// crossMergeGraph describes the commit graph above for the offline test backend
+var crossMergeGraph = `
+testdata/cross-merge/main     -> 8e030f4
+testdata/cross-merge/feature1 -> 1c97b65
+testdata/cross-merge/feature2 -> 46bf43e
+
+1c97b651f23907fd29956141d3e7a6bbc8c24671 <- 2ba409a 46bf43e : M2: Merge feature2 into feature1 (cross-merge point)
+46bf43eb956daf9599b77d4177f00b7e47c1f9d5 <- 18a2ae8         : F2: Feature2 development
+18a2ae89310b28343e7cd19200a8f2f565864d87 <- 8e030f4 74ec9ee : G: Merge feature1 into feature2 (first cross-merge)
+8e030f4bf6138adbb1a8c7c67d879bd6433c5130 <- 9b93392         : C: Main development 2
+2ba409ae3a792d20d3425b4b596515b2996301c7 <- 74ec9ee         : F1: Feature1 development
+74ec9ee6dd54286b15638541a9590d770cf039f2 <- 9b93392         : B: Feature1 initial development
+9b93392                                                     : A: Initial commit (common ancestor)
+`
+
 // TestCrossMerge tests cross-merge scenarios with complex merge relationships
 func TestCrossMerge(t *testing.T) {
 	testCases := []TestCase{
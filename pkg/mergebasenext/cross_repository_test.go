@@ -0,0 +1,121 @@
+package mergebasenext
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestParseCrossRepositoryRef tests recognizing refs of other repositories in the network
+func TestParseCrossRepositoryRef(t *testing.T) {
+	testCases := []struct {
+		Ref      string
+		OK       bool
+		Expected crossRepositoryRef
+	}{
+		{Ref: "main"},
+		{Ref: "feature/login"},
+		{Ref: "contributor:feature/login", OK: true, Expected: crossRepositoryRef{owner: "contributor", ref: "feature/login"}},
+		{Ref: "contributor:my-fork:main", OK: true, Expected: crossRepositoryRef{owner: "contributor", repo: "my-fork", ref: "main"}},
+		{Ref: ":main"},
+		{Ref: "contributor:"},
+		{Ref: "a:b:c:d"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Ref, func(t *testing.T) {
+			actual, ok := parseCrossRepositoryRef(tc.Ref)
+			if ok != tc.OK || actual != tc.Expected {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tc.Expected, tc.OK, actual, ok)
+			}
+		})
+	}
+}
+
+// TestGitHubBackendCrossRepository tests comparing with a branch of a fork, resolving the head in the fork
+func TestGitHubBackendCrossRepository(t *testing.T) {
+	memory, err := NewMemoryBackend(`
+main                -> b1
+contributor/feature -> c2
+
+c2 <- c1 : C2: Fork change 2
+c1 <- a1 : C1: Fork change 1
+b1 <- a1 : B: Main branch development
+a1       : A: Initial commit (merge-base)
+`)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	testCases := []struct {
+		Name string
+		Head string
+		Repo string
+	}{
+		{Name: "Owner", Head: "contributor:feature", Repo: "contributor/gh-merge-base-next"},
+		{Name: "OwnerAndRepository", Head: "contributor:renamed-fork:feature", Repo: "contributor/renamed-fork"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+				base, head, _ := strings.Cut(r.PathValue("basehead"), "...")
+				if head != "contributor:feature" {
+					t.Errorf("Expected the compare API head contributor:feature, got %s", head)
+				}
+				// the graph names the fork branch contributor/feature
+				comparison, err := memory.CompareCommits(r.Context(), base, strings.ReplaceAll(head, ":", "/"))
+				if err != nil {
+					http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(comparison)
+			})
+			mux.HandleFunc("GET /api/v3/repos/{owner}/{repo}/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+				if repo := r.PathValue("owner") + "/" + r.PathValue("repo"); repo != tc.Repo {
+					t.Errorf("Expected %s to be resolved in %s, got %s", r.PathValue("ref"), tc.Repo, repo)
+				}
+				sha, err := memory.GetCommitSHA1(r.Context(), r.PathValue("owner")+"/"+r.PathValue("ref"))
+				if err != nil {
+					http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+					return
+				}
+				fmt.Fprint(w, sha)
+			})
+
+			client := newTestServerClient(t, mux)
+			result, err := client.GetMergeBaseNext("main", tc.Head, 1)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.SHA != "c1" || result.Depth != 2 {
+				t.Errorf("Expected c1 at depth 2, got %s at depth %d", result.SHA, result.Depth)
+			}
+		})
+	}
+}
+
+// TestGitBackendCrossRepository tests reading owner:ref from the remote-tracking branch of the remote named owner
+func TestGitBackendCrossRepository(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	f.commit("C", "C: Fork change")
+	f.git("update-ref", "refs/remotes/contributor/feature", "C")
+	f.git("checkout", "--quiet", "main")
+	f.git("branch", "--quiet", "-D", "feature")
+	f.commit("B", "B: Main branch development")
+
+	client, err := NewLocalClient(context.Background(), f.dir)
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	result, err := client.GetMergeBaseNext("main", "contributor:feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != f.sha("C") {
+		t.Errorf("Expected C %s, got %s", f.sha("C"), result.SHA)
+	}
+}
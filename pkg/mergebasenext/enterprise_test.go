@@ -0,0 +1,66 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newEnterpriseServer returns a stand-in GitHub Enterprise Server serving a linear history of n commits under /api/v3,
+// failing the test on requests outside the API prefix
+func newEnterpriseServer(t *testing.T, n int) *httptest.Server {
+	api := newLongHistoryHandler(t, n, true)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v3/") {
+			t.Errorf("Request %s %s is not under the GitHub Enterprise Server API prefix", r.Method, r.URL.Path)
+		}
+		api.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestGitHubEnterpriseServerHost tests that the compare and commit requests of NewClient are sent to the API of the host
+// of HOST/OWNER/REPO or WithHostname
+func TestGitHubEnterpriseServerHost(t *testing.T) {
+	n := 3
+	server := newEnterpriseServer(t, n)
+	host := strings.TrimPrefix(server.URL, "https://")
+	testCases := []struct {
+		Name string
+		Repo string
+		Opts []ClientOption
+	}{
+		{Name: "host in repo", Repo: host + "/srz-zumix/gh-merge-base-next"},
+		{Name: "hostname", Repo: "srz-zumix/gh-merge-base-next", Opts: []ClientOption{WithHostname(host)}},
+		{Name: "hostname as URL", Repo: "srz-zumix/gh-merge-base-next", Opts: []ClientOption{WithHostname(server.URL + "/")}},
+		{Name: "same host in repo and hostname", Repo: host + "/srz-zumix/gh-merge-base-next", Opts: []ClientOption{WithHostname(host)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			opts := append([]ClientOption{WithToken("enterprise-token"), WithTransport(server.Client().Transport)}, tc.Opts...)
+			client, err := NewClient(context.Background(), tc.Repo, opts...)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			result, err := client.GetMergeBaseNext("main", "feature", 1)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.SHA != longHistorySHA(1) || result.Depth != n {
+				t.Errorf("Expected %s at depth %d, got %s at depth %d", longHistorySHA(1), n, result.SHA, result.Depth)
+			}
+		})
+	}
+}
+
+// TestHostnameMismatch tests that a repository on another host than WithHostname is rejected
+func TestHostnameMismatch(t *testing.T) {
+	_, err := NewClient(context.Background(), "github.com/srz-zumix/gh-merge-base-next", WithHostname("ghes.example.com"), WithToken("token"))
+	if !errors.Is(err, ErrHostMismatch) {
+		t.Errorf("Expected ErrHostMismatch, got %v", err)
+	}
+}
@@ -37,6 +37,15 @@ import (
 	"testing"
 )
 
+// errorCasesGraph describes the commit graph above for the offline test backend
+var errorCasesGraph = `
+testdata/error-cases/main    -> 6ec9889
+testdata/error-cases/feature -> 25c6e7a
+
+25c6e7a <- 6ec9889 : Feature branch commit
+6ec9889            : Initial commit for error testing
+`
+
 // TestErrorCases tests various error scenarios and edge cases
 func TestErrorCases(t *testing.T) {
 	errorTestCases := []ErrorTestCase{
@@ -24,17 +24,29 @@ import (
 	"testing"
 )
 
+// fastForwardGraph describes the commit graph above for the offline test backend
+var fastForwardGraph = `
+testdata/fast-forward/main    -> 476d315
+testdata/fast-forward/feature -> 6bbcf95
+
+6bbcf95e2eb308ae611d6c8ef67e1b46d04d8a5a <- f3295b2 : D: Feature work 3
+f3295b2c26f9cc8f96e3be8dcf11b34022bc1951 <- 463ec54 : C: Feature work 2
+463ec54c18fd7544fcf276aec8a3c1e185b61b6c <- 476d315 : B: Feature work 1
+476d315                                             : A: Initial commit (merge-base)
+`
+
 // TestFastForward tests fast-forward scenarios with linear commit history and depth validation
 func TestFastForward(t *testing.T) {
 
 	testCases := []TestCase{
 		{
-			Name:  "FindNextFromMainToFeatureFirstNext",
-			Base:  "476d315",
-			Head:  "testdata/fast-forward/feature",
-			SHA:   "463ec54c18fd7544fcf276aec8a3c1e185b61b6c",
-			Depth: 3,
-			Desc:  "Find next commit from main to feature (first next)",
+			Name:   "FindNextFromMainToFeatureFirstNext",
+			Base:   "476d315",
+			Head:   "testdata/fast-forward/feature",
+			SHA:    "463ec54c18fd7544fcf276aec8a3c1e185b61b6c",
+			Depth:  3,
+			Status: StatusAhead,
+			Desc:   "Find next commit from main to feature (first next)",
 		},
 		{
 			Name:  "FindNextFromIntermediateCommit",
@@ -53,20 +65,22 @@ func TestFastForward(t *testing.T) {
 			Desc:  "Find next commit from second to last commit (C: Feature work 2)",
 		},
 		{
-			Name:  "FindNextFromLastCommit",
-			Base:  "6bbcf95",
-			Head:  "testdata/fast-forward/feature",
-			SHA:   "",
-			Depth: 0,
-			Desc:  "Test when base equals head (no next commit available)",
+			Name:   "FindNextFromLastCommit",
+			Base:   "6bbcf95",
+			Head:   "testdata/fast-forward/feature",
+			SHA:    "",
+			Depth:  0,
+			Status: StatusIdentical,
+			Desc:   "Test when base equals head (no next commit available)",
 		},
 		{
-			Name:  "FindNextFromSameCommit",
-			Base:  "476d315",
-			Head:  "476d315",
-			SHA:   "",
-			Depth: 0,
-			Desc:  "Test when base equals head (no next commit available)",
+			Name:   "FindNextFromSameCommit",
+			Base:   "476d315",
+			Head:   "476d315",
+			SHA:    "",
+			Depth:  0,
+			Status: StatusIdentical,
+			Desc:   "Test when base equals head (no next commit available)",
 		},
 		{
 			Name:  "AutoDetectMergeBaseMainToFeatureWalkToHead",
@@ -77,12 +91,84 @@ func TestFastForward(t *testing.T) {
 			Desc:  "Auto-detect merge-base between main and feature, walk to feature",
 		},
 		{
-			Name:  "AutoDetectMergeBaseMainToFeatureWalkToBase",
-			Base:  "testdata/fast-forward/feature",
-			Head:  "testdata/fast-forward/main",
-			SHA:   "",
-			Depth: 0,
-			Desc:  "Auto-detect merge-base between main and feature, walk to main (no next commit)",
+			Name:   "AutoDetectMergeBaseMainToFeatureWalkToBase",
+			Base:   "testdata/fast-forward/feature",
+			Head:   "testdata/fast-forward/main",
+			SHA:    "",
+			Depth:  0,
+			Status: StatusUpToDate,
+			Desc:   "Auto-detect merge-base between main and feature, walk to main (no next commit)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t)
+		})
+	}
+}
+
+// TestFastForwardSteps tests returning the Nth commit along the first-parent path
+func TestFastForwardSteps(t *testing.T) {
+	testCases := []TestCase{
+		{
+			Name:  "SecondStepFromMainToFeature",
+			Base:  "testdata/fast-forward/main",
+			Head:  "testdata/fast-forward/feature",
+			Steps: 2,
+			SHA:   "f3295b2c26f9cc8f96e3be8dcf11b34022bc1951",
+			Depth: 2,
+			Desc:  "Return C: Feature work 2, two steps past the merge-base",
+		},
+		{
+			Name:  "StepsClampedAtHead",
+			Base:  "testdata/fast-forward/main",
+			Head:  "testdata/fast-forward/feature",
+			Steps: 5,
+			SHA:   "6bbcf95e2eb308ae611d6c8ef67e1b46d04d8a5a",
+			Depth: 1,
+			Desc:  "Return the head (D: Feature work 3) when steps exceed the path length",
+		},
+		{
+			Name:  "ZeroStepsReturnsNext",
+			Base:  "testdata/fast-forward/main",
+			Head:  "testdata/fast-forward/feature",
+			Steps: 0,
+			SHA:   "463ec54c18fd7544fcf276aec8a3c1e185b61b6c",
+			Depth: 3,
+			Desc:  "Treat steps below 1 as the merge-base-next commit",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Run(t)
+		})
+	}
+}
+
+// TestFastForwardPath tests listing the whole first-parent path on a linear history
+func TestFastForwardPath(t *testing.T) {
+	testCases := []PathTestCase{
+		{
+			Name: "ListFromMainToFeature",
+			Base: "testdata/fast-forward/main",
+			Head: "testdata/fast-forward/feature",
+			SHAs: []string{
+				"463ec54c18fd7544fcf276aec8a3c1e185b61b6c",
+				"f3295b2c26f9cc8f96e3be8dcf11b34022bc1951",
+				"6bbcf95e2eb308ae611d6c8ef67e1b46d04d8a5a",
+			},
+			Status: StatusAhead,
+			Desc:   "List every commit from merge-base-next (B) to feature head (D)",
+		},
+		{
+			Name:   "ListFromFeatureToMain",
+			Base:   "testdata/fast-forward/feature",
+			Head:   "testdata/fast-forward/main",
+			SHAs:   []string{},
+			Status: StatusUpToDate,
+			Desc:   "List nothing when head is already merged into base",
 		},
 	}
 
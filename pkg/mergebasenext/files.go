@@ -0,0 +1,102 @@
+package mergebasenext
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// FileSummary lists the files a commit changes with their line counts and CODEOWNERS owners.
+type FileSummary struct {
+	Files     []FileChange `json:"files"`
+	Additions int          `json:"additions"`
+	Deletions int          `json:"deletions"`
+	Changes   int          `json:"changes"`
+	// Owners is the sorted union of the owners of every file.
+	Owners []string `json:"owners"`
+}
+
+// FileChange is one file changed by a commit.
+type FileChange struct {
+	Filename  string   `json:"filename"`
+	Status    string   `json:"status"`
+	Additions int      `json:"additions"`
+	Deletions int      `json:"deletions"`
+	Changes   int      `json:"changes"`
+	Owners    []string `json:"owners"`
+}
+
+// getFileSummary summarizes the files changed by commits, with owners from the CODEOWNERS file of base.
+// A file changed by more than one of the commits is listed once, with its line counts summed,
+// the status of its first change, and removed when a later commit removes it.
+func (c *Client) getFileSummary(base string, commits ...*github.RepositoryCommit) (*FileSummary, error) {
+	files := []*github.CommitFile{}
+	for _, commit := range commits {
+		commitFiles, err := c.commitFiles(commit)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, commitFiles...)
+	}
+	owners, err := c.getCodeowners(base)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &FileSummary{
+		Files:  []FileChange{},
+		Owners: []string{},
+	}
+	for _, file := range files {
+		summary.Additions += file.GetAdditions()
+		summary.Deletions += file.GetDeletions()
+		summary.Changes += file.GetChanges()
+		index := slices.IndexFunc(summary.Files, func(change FileChange) bool { return change.Filename == file.GetFilename() })
+		if index >= 0 {
+			change := &summary.Files[index]
+			change.Additions += file.GetAdditions()
+			change.Deletions += file.GetDeletions()
+			change.Changes += file.GetChanges()
+			if file.GetStatus() == "removed" {
+				change.Status = "removed"
+			}
+			continue
+		}
+		change := FileChange{
+			Filename:  file.GetFilename(),
+			Status:    file.GetStatus(),
+			Additions: file.GetAdditions(),
+			Deletions: file.GetDeletions(),
+			Changes:   file.GetChanges(),
+			Owners:    owners.Owners(file.GetFilename()),
+		}
+		summary.Files = append(summary.Files, change)
+		for _, owner := range change.Owners {
+			if !slices.Contains(summary.Owners, owner) {
+				summary.Owners = append(summary.Owners, owner)
+			}
+		}
+	}
+	slices.Sort(summary.Owners)
+	return summary, nil
+}
+
+// getCodeowners reads the CODEOWNERS file of ref from the first location GitHub looks at.
+// Without a CODEOWNERS file, or with a backend that cannot read files, no file has owners.
+func (c *Client) getCodeowners(ref string) (*codeowners, error) {
+	reader, ok := c.backend.(FileReader)
+	if !ok {
+		return &codeowners{}, nil
+	}
+	for _, path := range codeownersPaths {
+		content, err := reader.GetFileContent(c.ctx, ref, path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if content != nil {
+			return parseCodeowners(content), nil
+		}
+	}
+	return &codeowners{}, nil
+}
@@ -0,0 +1,194 @@
+package mergebasenext
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// TestCodeowners tests resolving file owners with CODEOWNERS patterns, where the last matching rule wins
+func TestCodeowners(t *testing.T) {
+	owners := parseCodeowners([]byte(`
+# Default owners
+*                 @example/maintainers
+*.md              @example/docs
+/build/           @example/build
+docs/**/api.md    @alice
+apps/             @bob # any apps directory
+/scripts/*.sh     @carol @dave
+go.mod
+`))
+	testCases := []struct {
+		File   string
+		Owners []string
+	}{
+		{File: "main.go", Owners: []string{"@example/maintainers"}},
+		{File: "README.md", Owners: []string{"@example/docs"}},
+		{File: "build/ci/Dockerfile", Owners: []string{"@example/build"}},
+		{File: "tools/build/Dockerfile", Owners: []string{"@example/maintainers"}},
+		{File: "docs/api.md", Owners: []string{"@alice"}},
+		{File: "docs/v1/ref/api.md", Owners: []string{"@alice"}},
+		{File: "services/apps/web/main.go", Owners: []string{"@bob"}},
+		{File: "scripts/release.sh", Owners: []string{"@carol", "@dave"}},
+		{File: "scripts/ci/release.sh", Owners: []string{"@example/maintainers"}},
+		{File: "go.mod", Owners: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.File, func(t *testing.T) {
+			if got := owners.Owners(tc.File); !slices.Equal(got, tc.Owners) {
+				t.Errorf("Expected owners %v, got %v", tc.Owners, got)
+			}
+		})
+	}
+}
+
+// TestWithFiles tests summarizing the files of the next commit with owners from the CODEOWNERS file
+func TestWithFiles(t *testing.T) {
+	backend, err := NewMemoryBackend(`
+main    -> b1
+feature -> c1
+
+c1 <- a1 +src/app.go +docs/guide.md +LICENSE : C1: Feature change
+b1 <- a1                                     : B: Main branch development
+a1                                           : A: Initial commit (merge-base)
+`)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	backend.SetFileContent(".github/CODEOWNERS", []byte("src/ @alice\n*.md @bob @alice\n"))
+	client := NewClientWithBackend(context.Background(), backend, WithFiles())
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.Files == nil {
+		t.Fatalf("Expected a file summary")
+	}
+	expectedOwners := map[string][]string{
+		"src/app.go":    {"@alice"},
+		"docs/guide.md": {"@bob", "@alice"},
+		"LICENSE":       {},
+	}
+	if len(result.Files.Files) != len(expectedOwners) {
+		t.Fatalf("Expected %d files, got %d", len(expectedOwners), len(result.Files.Files))
+	}
+	for _, file := range result.Files.Files {
+		if !slices.Equal(file.Owners, expectedOwners[file.Filename]) {
+			t.Errorf("Expected owners of %s %v, got %v", file.Filename, expectedOwners[file.Filename], file.Owners)
+		}
+	}
+	if !slices.Equal(result.Files.Owners, []string{"@alice", "@bob"}) {
+		t.Errorf("Expected owners [@alice @bob], got %v", result.Files.Owners)
+	}
+}
+
+// TestGitBackendFiles tests file stats and CODEOWNERS lookup with the local git backend
+func TestGitBackendFiles(t *testing.T) {
+	f := newGitFixture(t)
+	f.write("CODEOWNERS", "*.go @gophers\n")
+	f.write("README.md", "readme\n")
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	f.write("main.go", "package main\n\nfunc main() {}\n")
+	f.write("README.md", "readme\nmore\n")
+	f.git("rm", "--quiet", "CODEOWNERS")
+	f.commit("C", "C: Feature change")
+
+	client, err := NewLocalClient(context.Background(), f.dir, WithFiles())
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	expected := []FileChange{
+		{Filename: "CODEOWNERS", Status: "removed", Additions: 0, Deletions: 1, Changes: 1, Owners: []string{}},
+		{Filename: "README.md", Status: "modified", Additions: 1, Deletions: 0, Changes: 1, Owners: []string{}},
+		{Filename: "main.go", Status: "added", Additions: 3, Deletions: 0, Changes: 3, Owners: []string{"@gophers"}},
+	}
+	if !slices.EqualFunc(result.Files.Files, expected, func(a FileChange, b FileChange) bool {
+		return a.Filename == b.Filename && a.Status == b.Status && a.Additions == b.Additions &&
+			a.Deletions == b.Deletions && a.Changes == b.Changes && slices.Equal(a.Owners, b.Owners)
+	}) {
+		t.Errorf("Expected files %+v, got %+v", expected, result.Files.Files)
+	}
+	if result.Files.Additions != 4 || result.Files.Deletions != 1 || result.Files.Changes != 5 {
+		t.Errorf("Unexpected totals +%d -%d (%d)", result.Files.Additions, result.Files.Deletions, result.Files.Changes)
+	}
+}
+
+// TestGitBackendQuotedPaths tests paths git would quote without -z, such as non-ASCII names and names with quotes or tabs
+func TestGitBackendQuotedPaths(t *testing.T) {
+	f := newGitFixture(t)
+	f.write("README.md", "readme\n")
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	names := []string{"docs/naïve.md", "say \"hi\".txt", "tab\there.txt"}
+	for _, name := range names {
+		f.write(name, "one\ntwo\n")
+	}
+	f.commit("C", "C: Feature change")
+
+	client, err := NewLocalClient(context.Background(), f.dir, WithFiles())
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	filenames := []string{}
+	for _, file := range result.Files.Files {
+		filenames = append(filenames, file.Filename)
+		if file.Status != "added" || file.Additions != 2 {
+			t.Errorf("Expected %q added with 2 lines, got %s with %d", file.Filename, file.Status, file.Additions)
+		}
+	}
+	if !slices.Equal(filenames, names) {
+		t.Errorf("Expected files %q, got %q", names, filenames)
+	}
+}
+
+// TestGroupWithFiles tests summarizing the files of every commit of a run, and the pull request of the group modes
+func TestGroupWithFiles(t *testing.T) {
+	backend, err := NewMemoryBackend(`
+main    -> b1
+feature -> c3
+
+c3 <- c2 @bob +README.md                  : C3: Other author
+c2 <- c1 @alice +src/app.go #7            : C2: Second change
+c1 <- a1 @alice +src/app.go +docs/a.md #7 : C1: First change
+b1 <- a1                                  : B: Main branch development
+a1                                        : A: Initial commit (merge-base)
+`)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	backend.SetFileContent("CODEOWNERS", []byte("src/ @alice\n"))
+	client := NewClientWithBackend(context.Background(), backend, WithFiles(), WithPullRequests())
+
+	byAuthor, err := client.GetMergeBaseNextGroup("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNextGroup failed: %v", err)
+	}
+	byPullRequest, err := client.GetMergeBaseNextByPullRequest("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNextByPullRequest failed: %v", err)
+	}
+	for name, group := range map[string]*MergeBaseNextGroup{"group-by-author": byAuthor, "by-pr": byPullRequest} {
+		if group.Count != 2 || group.PullRequest == nil || group.PullRequest.Number != 7 {
+			t.Errorf("%s: expected the run of 2 commits of pull request #7, got %d commits of %+v", name, group.Count, group.PullRequest)
+		}
+		if group.Files == nil {
+			t.Fatalf("%s: expected a file summary", name)
+		}
+		filenames := []string{}
+		for _, file := range group.Files.Files {
+			filenames = append(filenames, file.Filename)
+		}
+		if !slices.Equal(filenames, []string{"src/app.go", "docs/a.md"}) || !slices.Equal(group.Files.Owners, []string{"@alice"}) {
+			t.Errorf("%s: expected src/app.go once and docs/a.md owned by @alice, got %v owned by %v", name, filenames, group.Files.Owners)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package mergebasenext
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// MergeBaseNextGroup is a run of consecutive commits by one author on the first-parent path,
+// so that one merge covers one author's work.
+type MergeBaseNextGroup struct {
+	// Commit is the last commit of the run, the one to merge.
+	Commit *github.RepositoryCommit `json:"commit,omitempty"`
+	From   string                   `json:"from"`
+	To     string                   `json:"to"`
+	Author string                   `json:"author"`
+	Count  int                      `json:"count"`
+	Depth  int                      `json:"depth"`
+	Status Status                   `json:"status"`
+	// Base and Head are the refs compared, and MergeBase is the SHA of their merge-base.
+	Base      string `json:"base"`
+	Head      string `json:"head"`
+	MergeBase string `json:"mergeBase"`
+	// MergeBaseCommit and MergeBases are set with WithMergeBases, as for MergeBaseNext.
+	MergeBaseCommit *github.RepositoryCommit `json:"mergeBaseCommit,omitempty"`
+	MergeBases      []string                 `json:"mergeBases,omitempty"`
+	// Skipped lists the commits passed over by the skip filter before the run.
+	Skipped []string `json:"skipped,omitempty"`
+	// PullRequest is the pull request the run belongs to, for GetMergeBaseNextByPullRequest,
+	// or the pull request Commit belongs to, when requested with WithPullRequests.
+	PullRequest *PullRequestInfo `json:"pullRequest,omitempty"`
+	// Files summarizes the files the commits of the run change, when requested with WithFiles.
+	Files *FileSummary `json:"files,omitempty"`
+}
+
+// GetMergeBaseNextGroup returns the run of commits starting at the commit GetMergeBaseNext would return
+// and extending along the first-parent path toward head as long as the commits share its author.
+// Depth is the number of commits from To to head, inclusive.
+func (c *Client) GetMergeBaseNextGroup(base string, head string, steps int) (*MergeBaseNextGroup, error) {
+	mainline, err := c.getMainlinePath(base, head)
+	if err != nil {
+		return nil, err
+	}
+	path := mainline.commits
+	group := newMergeBaseNextGroup(base, head, mainline)
+	if len(path) == 0 {
+		return group, nil
+	}
+
+	from, skipped, err := c.selectNext(path, steps)
+	if err != nil {
+		return nil, err
+	}
+	author := commitAuthor(path[from])
+	to := from
+	for to+1 < len(path) && commitAuthor(path[to+1]) == author {
+		to++
+	}
+	group.setRun(path, from, to, author, skipped)
+	if c.withPullRequests {
+		group.PullRequest, err = c.commitPullRequest(group.Commit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := c.addGroupFiles(group, path[from:to+1]); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// newMergeBaseNextGroup returns the group of base and head on mainline without a run, as it is when there is no next commit.
+func newMergeBaseNextGroup(base string, head string, mainline *mainlinePath) *MergeBaseNextGroup {
+	return &MergeBaseNextGroup{
+		Status:          mainline.status,
+		Base:            base,
+		Head:            head,
+		MergeBase:       mainline.mergeBase,
+		MergeBaseCommit: mainline.mergeBaseCommit,
+		MergeBases:      mainline.mergeBases,
+	}
+}
+
+// setRun makes the commits of path from index from to index to the run of group, by author,
+// after the commits skipped before it.
+func (g *MergeBaseNextGroup) setRun(path []*github.RepositoryCommit, from int, to int, author string, skipped []string) {
+	g.Commit = path[to]
+	g.From = path[from].GetSHA()
+	g.To = path[to].GetSHA()
+	g.Author = author
+	g.Count = to - from + 1
+	g.Depth = len(path) - to
+	g.Skipped = skipped
+}
+
+// addGroupFiles summarizes the files changed by commits, the run of group, when requested with WithFiles.
+func (c *Client) addGroupFiles(group *MergeBaseNextGroup, commits []*github.RepositoryCommit) error {
+	if !c.withFiles {
+		return nil
+	}
+	files, err := c.getFileSummary(group.Base, commits...)
+	if err != nil {
+		return err
+	}
+	group.Files = files
+	return nil
+}
+
+// commitAuthor identifies the author of commit by GitHub login, falling back to the git author name and email.
+func commitAuthor(commit *github.RepositoryCommit) string {
+	if login := commit.GetAuthor().GetLogin(); login != "" {
+		return login
+	}
+	author := commit.GetCommit().GetAuthor()
+	if author.GetEmail() == "" {
+		return author.GetName()
+	}
+	return fmt.Sprintf("%s <%s>", author.GetName(), author.GetEmail())
+}
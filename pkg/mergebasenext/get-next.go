@@ -1,63 +1,237 @@
 package mergebasenext
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/google/go-github/v88/github"
-	"github.com/srz-zumix/go-gh-extension/pkg/gh"
+)
+
+var (
+	// ErrCycleDetected is returned when the first-parent walk comes back to a commit it already visited.
+	ErrCycleDetected = errors.New("cycle detected in first-parent path")
+	// ErrMaxDepthExceeded is returned when the first-parent walk is longer than the configured maximum depth.
+	ErrMaxDepthExceeded = errors.New("maximum depth exceeded")
+	// ErrParentIndexOutOfRange is returned when a merge commit on the path has fewer parents than the configured parent index.
+	ErrParentIndexOutOfRange = errors.New("parent index out of range")
+)
+
+// Status describes how head relates to base in a comparison.
+type Status string
+
+const (
+	// StatusIdentical means base and head point to the same commit.
+	StatusIdentical Status = "identical"
+	// StatusAhead means head contains commits that base does not, and base is an ancestor of head.
+	StatusAhead Status = "ahead"
+	// StatusUpToDate means head is an ancestor of base, so there is nothing to merge.
+	StatusUpToDate Status = "up-to-date"
+	// StatusDiverged means base and head both contain commits the other does not.
+	StatusDiverged Status = "diverged"
+	// StatusTruncated means head was missing from the commits returned by the comparison.
+	StatusTruncated Status = "truncated"
 )
 
 type MergeBaseNext struct {
 	Commit *github.RepositoryCommit `json:"commit,omitempty"`
 	SHA    string                   `json:"sha"`
 	Depth  int                      `json:"depth"`
+	Status Status                   `json:"status"`
+	Check  *MergeCheck              `json:"check,omitempty"`
+	// Base and Head are the refs compared, and MergeBase is the SHA of their merge-base.
+	Base      string `json:"base"`
+	Head      string `json:"head"`
+	MergeBase string `json:"mergeBase"`
+	// MergeBaseCommit is the merge-base commit, and MergeBases lists every merge-base candidate starting with it,
+	// when requested with WithMergeBases. Criss-cross histories have more than one candidate.
+	MergeBaseCommit *github.RepositoryCommit `json:"mergeBaseCommit,omitempty"`
+	MergeBases      []string                 `json:"mergeBases,omitempty"`
+	// Skipped lists the commits passed over by the skip filter, which are merged together with Commit.
+	Skipped []string `json:"skipped,omitempty"`
+	// PullRequest is the pull request Commit belongs to, when requested with WithPullRequests.
+	PullRequest *PullRequestInfo `json:"pullRequest,omitempty"`
+	// Files summarizes the files Commit changes, when requested with WithFiles.
+	Files *FileSummary `json:"files,omitempty"`
 }
 
-func (c *Client) GetMergeBaseNext(base string, head string) (*MergeBaseNext, error) {
-	commitsComparison, err := gh.CompareCommits(c.ctx, c.client, c.repo, base, head)
+// GetMergeBaseNext returns the commit steps commits past the merge-base of base and head on the first-parent path to head.
+// A steps value of 1 or less returns the merge-base-next commit, and values beyond head are clamped to head.
+// Commits matching the client's skip filter are passed over and do not count as steps.
+// Depth is the number of commits from the returned commit to head, inclusive.
+func (c *Client) GetMergeBaseNext(base string, head string, steps int) (*MergeBaseNext, error) {
+	mainline, err := c.getMainlinePath(base, head)
 	if err != nil {
 		return nil, err
 	}
+	path, status := mainline.commits, mainline.status
+	if len(path) == 0 {
+		return &MergeBaseNext{
+			Commit:          nil,
+			SHA:             "",
+			Depth:           0,
+			Status:          status,
+			Base:            base,
+			Head:            head,
+			MergeBase:       mainline.mergeBase,
+			MergeBaseCommit: mainline.mergeBaseCommit,
+			MergeBases:      mainline.mergeBases,
+		}, nil
+	}
 
-	headSHA, err := gh.GetCommitSHA1(c.ctx, c.client, c.repo, head)
+	index, skipped, err := c.selectNext(path, steps)
 	if err != nil {
 		return nil, err
 	}
-	headRepositoryCommit, err := findCommit(commitsComparison, headSHA)
+	nextCommit := path[index]
+	result := &MergeBaseNext{
+		Commit:          nextCommit,
+		SHA:             nextCommit.GetSHA(),
+		Depth:           len(path) - index,
+		Status:          status,
+		Base:            base,
+		Head:            head,
+		MergeBase:       mainline.mergeBase,
+		MergeBaseCommit: mainline.mergeBaseCommit,
+		MergeBases:      mainline.mergeBases,
+		Skipped:         skipped,
+	}
+	if c.withPullRequests {
+		result.PullRequest, err = c.commitPullRequest(nextCommit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.withFiles {
+		result.Files, err = c.getFileSummary(base, nextCommit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// mainlinePath is the mainline path from the merge-base of base and head to head.
+type mainlinePath struct {
+	// commits are ordered from the merge-base-next commit to head.
+	commits   []*github.RepositoryCommit
+	status    Status
+	mergeBase string
+	// mergeBaseCommit and mergeBases are only set with WithMergeBases.
+	mergeBaseCommit *github.RepositoryCommit
+	mergeBases      []string
+}
+
+// getMainlinePath returns the mainline (first-parent by default) path from the merge-base of base and head to head.
+func (c *Client) getMainlinePath(base string, head string) (*mainlinePath, error) {
+	commitsComparison, err := c.backend.CompareCommits(c.ctx, base, head)
 	if err != nil {
-		return &MergeBaseNext{
-			Commit: nil,
-			SHA:    "",
-			Depth:  0,
-		}, nil
+		return nil, err
 	}
 
-	nextCommit, depth := walkToFirstParent(commitsComparison, headRepositoryCommit, 1)
+	headSHA, err := c.backend.GetCommitSHA1(c.ctx, head)
+	if err != nil {
+		return nil, err
+	}
 
-	return &MergeBaseNext{
-		Commit: nextCommit,
-		SHA:    nextCommit.GetSHA(),
-		Depth:  depth,
-	}, nil
+	mainline := &mainlinePath{
+		status:    comparisonStatus(commitsComparison),
+		mergeBase: commitsComparison.GetMergeBaseCommit().GetSHA(),
+	}
+	if c.withMergeBases || (c.mergeBaseReport != "" && c.mergeBaseReport != MergeBaseReportGitHub) {
+		mergeBases, err := c.listMergeBases(base, head, commitsComparison)
+		if err != nil {
+			return nil, err
+		}
+		mergeBases, err = c.selectMergeBase(base, head, mergeBases)
+		if err != nil {
+			return nil, err
+		}
+		mainline.mergeBase = mergeBases[0].GetSHA()
+		if c.withMergeBases {
+			// the report only selects mergeBase; the commit and the candidates are reported when requested
+			mainline.mergeBaseCommit = mergeBases[0]
+			mainline.mergeBases = commitSHAs(mergeBases)
+		}
+	}
+	graph := newComparisonGraph(commitsComparison)
+	headRepositoryCommit, ok := graph.Commit(headSHA)
+	if !ok {
+		if mainline.status == StatusAhead || mainline.status == StatusDiverged {
+			mainline.status = StatusTruncated
+		}
+		return mainline, nil
+	}
+	if len(commitsComparison.Commits) < commitsComparison.GetTotalCommits() {
+		mainline.status = StatusTruncated
+	}
+
+	path, err := walkToMainline(graph, headRepositoryCommit, c.parentIndex, c.maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(path)
+	mainline.commits = path
+	return mainline, nil
 }
 
-func findCommit(commitsComparison *github.CommitsComparison, sha string) (*github.RepositoryCommit, error) {
-	for i := len(commitsComparison.Commits) - 1; i >= 0; i-- {
-		commit := commitsComparison.Commits[i]
-		if commit.GetSHA() == sha {
-			return commit, nil
-		}
+// comparisonStatus converts the status reported by the compare API into a Status.
+func comparisonStatus(commitsComparison *github.CommitsComparison) Status {
+	switch commitsComparison.GetStatus() {
+	case "identical":
+		return StatusIdentical
+	case "behind":
+		return StatusUpToDate
+	case "diverged":
+		return StatusDiverged
+	default:
+		return StatusAhead
 	}
-	return nil, fmt.Errorf("commit not found")
 }
 
-func walkToFirstParent(commitsComparison *github.CommitsComparison, commit *github.RepositoryCommit, depth int) (*github.RepositoryCommit, int) {
-	if len(commit.Parents) == 0 {
-		return commit, depth
+// walkToMainline returns commit and its mainline ancestors within graph, head first.
+// The mainline follows the parent at parentIndex of each merge commit; commits with a single parent follow it.
+// It fails when a merge commit has no parent at parentIndex, when a commit is visited twice,
+// or when the path grows longer than maxDepth (if positive).
+func walkToMainline(graph CommitGraph, commit *github.RepositoryCommit, parentIndex int, maxDepth int) ([]*github.RepositoryCommit, error) {
+	path := []*github.RepositoryCommit{}
+	visited := map[string]bool{}
+	for {
+		sha := commit.GetSHA()
+		if visited[sha] {
+			return nil, fmt.Errorf("%w: commit %s is its own mainline ancestor", ErrCycleDetected, sha)
+		}
+		if maxDepth > 0 && len(path) >= maxDepth {
+			return nil, fmt.Errorf("%w: the mainline path from %s is longer than %d commits", ErrMaxDepthExceeded, path[0].GetSHA(), maxDepth)
+		}
+		visited[sha] = true
+		path = append(path, commit)
+
+		parent, err := mainlineParent(commit, parentIndex)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return path, nil
+		}
+		parentCommit, ok := graph.Commit(parent.GetSHA())
+		if !ok {
+			return path, nil
+		}
+		commit = parentCommit
 	}
-	parentCommit, err := findCommit(commitsComparison, commit.Parents[0].GetSHA())
-	if err != nil {
-		return commit, depth
+}
+
+// mainlineParent returns the parent of commit the walk follows, or nil for a root commit.
+func mainlineParent(commit *github.RepositoryCommit, parentIndex int) (*github.Commit, error) {
+	switch {
+	case len(commit.Parents) == 0:
+		return nil, nil
+	case len(commit.Parents) == 1:
+		return commit.Parents[0], nil
+	case parentIndex < 0 || parentIndex >= len(commit.Parents):
+		return nil, fmt.Errorf("%w: merge commit %s has %d parents, parent index %d requested", ErrParentIndexOutOfRange, commit.GetSHA(), len(commit.Parents), parentIndex)
+	default:
+		return commit.Parents[parentIndex], nil
 	}
-	return walkToFirstParent(commitsComparison, parentCommit, depth+1)
 }
@@ -0,0 +1,38 @@
+package mergebasenext
+
+import (
+	"github.com/google/go-github/v88/github"
+)
+
+type MergeBasePath struct {
+	Commits   []*github.RepositoryCommit `json:"commits"`
+	Status    Status                     `json:"status"`
+	Base      string                     `json:"base"`
+	Head      string                     `json:"head"`
+	MergeBase string                     `json:"mergeBase"`
+	// MergeBaseCommit and MergeBases are set with WithMergeBases, as for MergeBaseNext.
+	MergeBaseCommit *github.RepositoryCommit `json:"mergeBaseCommit,omitempty"`
+	MergeBases      []string                 `json:"mergeBases,omitempty"`
+}
+
+// GetMergeBasePath returns every commit on the first-parent path from the merge-base of base and head to head.
+// Commits are ordered from the merge-base-next commit to head.
+func (c *Client) GetMergeBasePath(base string, head string) (*MergeBasePath, error) {
+	mainline, err := c.getMainlinePath(base, head)
+	if err != nil {
+		return nil, err
+	}
+	path, status := mainline.commits, mainline.status
+	if path == nil {
+		path = []*github.RepositoryCommit{}
+	}
+	return &MergeBasePath{
+		Commits:         path,
+		Status:          status,
+		Base:            base,
+		Head:            head,
+		MergeBase:       mainline.mergeBase,
+		MergeBaseCommit: mainline.mergeBaseCommit,
+		MergeBases:      mainline.mergeBases,
+	}, nil
+}
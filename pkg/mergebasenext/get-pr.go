@@ -0,0 +1,127 @@
+package mergebasenext
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// ErrPullRequestsUnsupported is returned when the client backend cannot find the pull requests of a commit.
+var ErrPullRequestsUnsupported = errors.New("backend does not support pull request lookups")
+
+// PullRequestInfo summarizes the pull request a commit belongs to.
+type PullRequestInfo struct {
+	Number int      `json:"number"`
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+	Labels []string `json:"labels"`
+	URL    string   `json:"url"`
+}
+
+// GetMergeBaseNextByPullRequest returns the next step in terms of pull requests: the run of commits on the first-parent path
+// that belong to the pull request of the merge-base-next commit, such as a PR merge commit or the commits of a rebase merge.
+// A steps value greater than 1 advances one pull request at a time, clamped at head, and commits without a pull request are a step of their own.
+// Author is the author of the pull request, or of the commit when it has no pull request.
+func (c *Client) GetMergeBaseNextByPullRequest(base string, head string, steps int) (*MergeBaseNextGroup, error) {
+	mainline, err := c.getMainlinePath(base, head)
+	if err != nil {
+		return nil, err
+	}
+	path := mainline.commits
+	group := newMergeBaseNextGroup(base, head, mainline)
+	if len(path) == 0 {
+		return group, nil
+	}
+
+	from, skipped, err := c.selectNext(path, 1)
+	if err != nil {
+		return nil, err
+	}
+	pulls := map[string]*PullRequestInfo{}
+	pullRequestOf := func(commit *github.RepositoryCommit) (*PullRequestInfo, error) {
+		if pr, ok := pulls[commit.GetSHA()]; ok {
+			return pr, nil
+		}
+		pr, err := c.commitPullRequest(commit)
+		if err != nil {
+			return nil, err
+		}
+		pulls[commit.GetSHA()] = pr
+		return pr, nil
+	}
+
+	for step := 1; ; step++ {
+		pr, err := pullRequestOf(path[from])
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		for pr != nil && to+1 < len(path) {
+			next, err := pullRequestOf(path[to+1])
+			if err != nil {
+				return nil, err
+			}
+			if next == nil || next.Number != pr.Number {
+				break
+			}
+			to++
+		}
+		if step < max(steps, 1) && to+1 < len(path) {
+			from = to + 1
+			continue
+		}
+
+		author := commitAuthor(path[from])
+		if pr != nil && pr.Author != "" {
+			author = pr.Author
+		}
+		group.setRun(path, from, to, author, skipped)
+		group.PullRequest = pr
+		if err := c.addGroupFiles(group, path[from:to+1]); err != nil {
+			return nil, err
+		}
+		return group, nil
+	}
+}
+
+// commitPullRequest returns the pull request commit belongs to, or nil when it has none.
+// A pull request merged as commit is preferred, then any merged pull request, then an open one.
+func (c *Client) commitPullRequest(commit *github.RepositoryCommit) (*PullRequestInfo, error) {
+	lister, ok := c.backend.(PullRequestLister)
+	if !ok {
+		return nil, ErrPullRequestsUnsupported
+	}
+	pulls, err := lister.ListPullRequestsWithCommit(c.ctx, commit.GetSHA())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests of %s: %w", commit.GetSHA(), err)
+	}
+	var found *github.PullRequest
+	for _, pr := range pulls {
+		merged := pr.GetMerged() || pr.MergedAt != nil
+		switch {
+		case merged && pr.GetMergeCommitSHA() == commit.GetSHA():
+			return newPullRequestInfo(pr), nil
+		case found == nil, merged && !(found.GetMerged() || found.MergedAt != nil):
+			found = pr
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return newPullRequestInfo(found), nil
+}
+
+func newPullRequestInfo(pr *github.PullRequest) *PullRequestInfo {
+	labels := []string{}
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return &PullRequestInfo{
+		Number: pr.GetNumber(),
+		Title:  pr.GetTitle(),
+		Author: pr.GetUser().GetLogin(),
+		Labels: labels,
+		URL:    pr.GetHTMLURL(),
+	}
+}
@@ -0,0 +1,331 @@
+package mergebasenext
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// gitLogFormat separates the fields of a commit with NUL and terminates each commit with RS.
+const gitLogFormat = "%H%x00%P%x00%an%x00%ae%x00%aI%x00%cn%x00%ce%x00%cI%x00%B%x1e"
+
+var (
+	// mergePullRequestPattern matches the message of a merge commit created by merging a pull request on GitHub.
+	mergePullRequestPattern = regexp.MustCompile(`^Merge pull request #(\d+) from \S+\n\n([^\n]*)`)
+	// squashPullRequestPattern matches the subject of a commit created by squash merging a pull request on GitHub.
+	squashPullRequestPattern = regexp.MustCompile(`^(.*) \(#(\d+)\)$`)
+)
+
+// GitBackend reads commits of a local git repository with git plumbing commands.
+type GitBackend struct {
+	dir string
+}
+
+// NewGitBackend creates a backend for the git repository at dir.
+// An empty dir means the current working directory.
+func NewGitBackend(dir string) (*GitBackend, error) {
+	b := &GitBackend{dir: dir}
+	if _, err := b.git(context.Background(), "rev-parse", "--git-dir"); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// CompareCommits compares base and head the same way the GitHub compare API does.
+func (b *GitBackend) CompareCommits(ctx context.Context, base string, head string) (*github.CommitsComparison, error) {
+	baseSHA, err := b.GetCommitSHA1(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.GetCommitSHA1(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	mergeBase, err := b.git(ctx, "merge-base", baseSHA, headSHA)
+	if err != nil {
+		return nil, fmt.Errorf("no common ancestor between %s and %s: %w", base, head, err)
+	}
+	aheadBy, err := b.countCommits(ctx, baseSHA+".."+headSHA)
+	if err != nil {
+		return nil, err
+	}
+	behindBy, err := b.countCommits(ctx, headSHA+".."+baseSHA)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := b.logCommits(ctx, baseSHA+".."+headSHA)
+	if err != nil {
+		return nil, err
+	}
+	mergeBaseCommits, err := b.logCommits(ctx, "--no-walk", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &github.CommitsComparison{
+		BaseCommit:      &github.RepositoryCommit{SHA: github.Ptr(baseSHA)},
+		MergeBaseCommit: mergeBaseCommits[0],
+		Status:          github.Ptr(compareStatus(aheadBy, behindBy)),
+		AheadBy:         github.Ptr(aheadBy),
+		BehindBy:        github.Ptr(behindBy),
+		TotalCommits:    github.Ptr(len(commits)),
+		Commits:         commits,
+	}, nil
+}
+
+// ListMergeBases lists every best common ancestor of base and head, like git merge-base --all.
+func (b *GitBackend) ListMergeBases(ctx context.Context, base string, head string, comparison *github.CommitsComparison) ([]*github.RepositoryCommit, error) {
+	baseSHA, err := b.GetCommitSHA1(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.GetCommitSHA1(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	out, err := b.git(ctx, "merge-base", "--all", baseSHA, headSHA)
+	if err != nil {
+		return nil, fmt.Errorf("no common ancestor between %s and %s: %w", base, head, err)
+	}
+	return b.logCommits(ctx, append([]string{"--no-walk"}, strings.Fields(out)...)...)
+}
+
+// GetCommitSHA1 resolves ref to a commit SHA.
+// A ref of another repository, written owner:ref or owner:repo:ref, is read from the remote-tracking branch of the remote named owner.
+func (b *GitBackend) GetCommitSHA1(ctx context.Context, ref string) (string, error) {
+	revision := ref
+	if crossRef, ok := parseCrossRepositoryRef(ref); ok {
+		revision = "refs/remotes/" + crossRef.owner + "/" + crossRef.ref
+	}
+	sha, err := b.git(ctx, "rev-parse", "--verify", "--end-of-options", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("error resolving %s: %w", ref, err)
+	}
+	return sha, nil
+}
+
+func (b *GitBackend) countCommits(ctx context.Context, revisionRange string) (int, error) {
+	out, err := b.git(ctx, "rev-list", "--count", revisionRange)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// logCommits lists the commits selected by the git log arguments args, oldest first like the compare API.
+func (b *GitBackend) logCommits(ctx context.Context, args ...string) ([]*github.RepositoryCommit, error) {
+	out, err := b.git(ctx, append([]string{"log", "--reverse", "--topo-order", "--format=" + gitLogFormat}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	commits := []*github.RepositoryCommit{}
+	for record := range strings.SplitSeq(out, "\x1e") {
+		record = strings.TrimPrefix(record, "\n")
+		if record == "" {
+			continue
+		}
+		commit, err := parseGitLogRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func parseGitLogRecord(record string) (*github.RepositoryCommit, error) {
+	fields := strings.SplitN(record, "\x00", 9)
+	if len(fields) != 9 {
+		return nil, fmt.Errorf("unexpected git log output: %q", record)
+	}
+	authorDate, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return nil, err
+	}
+	committerDate, err := time.Parse(time.RFC3339, fields[7])
+	if err != nil {
+		return nil, err
+	}
+	parents := []*github.Commit{}
+	for parent := range strings.FieldsSeq(fields[1]) {
+		parents = append(parents, &github.Commit{SHA: github.Ptr(parent)})
+	}
+	return &github.RepositoryCommit{
+		SHA: github.Ptr(fields[0]),
+		Commit: &github.Commit{
+			SHA:       github.Ptr(fields[0]),
+			Message:   github.Ptr(strings.TrimSuffix(fields[8], "\n")),
+			Author:    &github.CommitAuthor{Name: github.Ptr(fields[2]), Email: github.Ptr(fields[3]), Date: &github.Timestamp{Time: authorDate}},
+			Committer: &github.CommitAuthor{Name: github.Ptr(fields[5]), Email: github.Ptr(fields[6]), Date: &github.Timestamp{Time: committerDate}},
+		},
+		Parents: parents,
+	}, nil
+}
+
+// ListCommitFiles lists the files changed by the commit sha, compared with its first parent.
+// Renames are reported as a removed and an added file, and binary files have no line counts.
+func (b *GitBackend) ListCommitFiles(ctx context.Context, sha string) ([]*github.CommitFile, error) {
+	// -z keeps paths with special characters unquoted
+	diffTree := []string{"diff-tree", "-z", "--root", "--no-commit-id", "--no-renames", "-r", "-m", "--first-parent"}
+	statuses, err := b.git(ctx, append(diffTree, "--name-status", sha)...)
+	if err != nil {
+		return nil, err
+	}
+	numstat, err := b.git(ctx, append(diffTree, "--numstat", sha)...)
+	if err != nil {
+		return nil, err
+	}
+	files := []*github.CommitFile{}
+	byName := map[string]*github.CommitFile{}
+	// --name-status -z separates the status and the path of each file with NUL
+	fields := strings.Split(strings.TrimSuffix(statuses, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, name := fields[i], fields[i+1]
+		file := &github.CommitFile{
+			Filename: github.Ptr(name),
+			Status:   github.Ptr(gitFileStatus(status)),
+		}
+		files = append(files, file)
+		byName[name] = file
+	}
+	for record := range strings.SplitSeq(numstat, "\x00") {
+		fields := strings.SplitN(record, "\t", 3)
+		if len(fields) != 3 || byName[fields[2]] == nil {
+			continue
+		}
+		// binary files report "-" for both counts
+		additions, _ := strconv.Atoi(fields[0])
+		deletions, _ := strconv.Atoi(fields[1])
+		file := byName[fields[2]]
+		file.Additions = github.Ptr(additions)
+		file.Deletions = github.Ptr(deletions)
+		file.Changes = github.Ptr(additions + deletions)
+	}
+	return files, nil
+}
+
+// gitFileStatus converts a git diff status letter into the file status of the GitHub API.
+func gitFileStatus(status string) string {
+	switch status {
+	case "A":
+		return "added"
+	case "D":
+		return "removed"
+	case "T":
+		return "changed"
+	default:
+		return "modified"
+	}
+}
+
+// GetFileContent returns the content of the file at path in ref, or nil when the file does not exist.
+func (b *GitBackend) GetFileContent(ctx context.Context, ref string, path string) ([]byte, error) {
+	if _, ok := parseCrossRepositoryRef(ref); ok {
+		sha, err := b.GetCommitSHA1(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		ref = sha
+	}
+	object := ref + ":" + path
+	if _, err := b.git(ctx, "cat-file", "-e", object); err != nil {
+		return nil, nil
+	}
+	content, err := b.git(ctx, "cat-file", "blob", object)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// ListPullRequestsWithCommit recognizes the pull request merged by the commit sha from the message
+// GitHub writes for merge and squash merges. Commits merged by other means have no pull request.
+func (b *GitBackend) ListPullRequestsWithCommit(ctx context.Context, sha string) ([]*github.PullRequest, error) {
+	message, err := b.git(ctx, "log", "-1", "--format=%B", sha)
+	if err != nil {
+		return nil, err
+	}
+	number, title := "", ""
+	subject, _, _ := strings.Cut(message, "\n")
+	if m := mergePullRequestPattern.FindStringSubmatch(message); m != nil {
+		number, title = m[1], m[2]
+	} else if m := squashPullRequestPattern.FindStringSubmatch(subject); m != nil {
+		number, title = m[2], m[1]
+	} else {
+		return []*github.PullRequest{}, nil
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, err
+	}
+	return []*github.PullRequest{{
+		Number:         github.Ptr(n),
+		State:          github.Ptr("closed"),
+		Title:          github.Ptr(title),
+		Merged:         github.Ptr(true),
+		MergeCommitSHA: github.Ptr(sha),
+	}}, nil
+}
+
+// CheckMerge test-merges head into base with git merge-tree (git 2.38 or later), without touching the work tree.
+func (b *GitBackend) CheckMerge(ctx context.Context, base string, head string) (*MergeCheck, error) {
+	baseSHA, err := b.GetCommitSHA1(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.GetCommitSHA1(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	out, err := b.git(ctx, "merge-tree", "--write-tree", "--name-only", "--no-messages", baseSHA, headSHA)
+	var exitErr *exec.ExitError
+	conflict := errors.As(err, &exitErr) && exitErr.ExitCode() == 1
+	if err != nil && !conflict {
+		return nil, err
+	}
+	// The first line is the merged tree; conflicting files follow
+	lines := strings.Split(out, "\n")
+	files := []string{}
+	if conflict {
+		for _, file := range lines[1:] {
+			if file != "" && !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+	return &MergeCheck{
+		Conflict: conflict,
+		Files:    files,
+	}, nil
+}
+
+// git runs a git command in the repository and returns its trimmed standard output.
+// The output is also returned when the command fails, for commands like merge-tree that report results through their exit status.
+func (b *GitBackend) git(ctx context.Context, args ...string) (string, error) {
+	subcommand := args[0]
+	if b.dir != "" {
+		args = append([]string{"-C", b.dir}, args...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	out := strings.TrimSpace(stdout.String())
+	if err != nil {
+		if stderr.Len() > 0 {
+			return out, fmt.Errorf("git %s: %s: %w", subcommand, strings.TrimSpace(stderr.String()), err)
+		}
+		return out, fmt.Errorf("git %s: %w", subcommand, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,176 @@
+package mergebasenext
+
+/*
+## Local Fixture Commit Graph
+
+```text
+*   M (main)    Merge feature into main
+|\
+| * D (feature) D: Feature development 2
+| * C           C: Feature development 1
+* | B           B: Main branch development
+|/
+* A             A: Initial commit (merge-base)
+```
+
+The fixture repository is generated with git in a temporary directory,
+so the local backend is tested without network access.
+*/
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitFixture is a throwaway git repository used to test the local backend
+type gitFixture struct {
+	t   *testing.T
+	dir string
+}
+
+func newGitFixture(t *testing.T) *gitFixture {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	f := &gitFixture{t: t, dir: t.TempDir()}
+	f.git("init", "--quiet", "--initial-branch=main")
+	return f
+}
+
+func (f *gitFixture) git(args ...string) string {
+	f.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", f.dir}, args...)...)
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=Fixture", "GIT_AUTHOR_EMAIL=fixture@example.com",
+		"GIT_COMMITTER_NAME=Fixture", "GIT_COMMITTER_EMAIL=fixture@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL=/dev/null",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit creates an empty commit and tags it with name
+func (f *gitFixture) commit(name string, message string) {
+	f.git("commit", "--quiet", "--allow-empty", "-m", message)
+	f.git("tag", name)
+}
+
+// write writes a file in the work tree and stages it
+func (f *gitFixture) write(name string, content string) {
+	f.t.Helper()
+	path := filepath.Join(f.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		f.t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		f.t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	f.git("add", name)
+}
+
+func (f *gitFixture) sha(ref string) string {
+	return f.git("rev-parse", ref)
+}
+
+func newSimpleMergeFixture(t *testing.T) *gitFixture {
+	f := newGitFixture(t)
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	f.commit("C", "C: Feature development 1")
+	f.commit("D", "D: Feature development 2")
+	f.git("checkout", "--quiet", "main")
+	f.commit("B", "B: Main branch development")
+	return f
+}
+
+// TestGitBackendError tests that a failed git command reports its stderr and keeps the exit status
+func TestGitBackendError(t *testing.T) {
+	f := newGitFixture(t)
+	backend, err := NewGitBackend(f.dir)
+	if err != nil {
+		t.Fatalf("NewGitBackend failed: %v", err)
+	}
+	_, err = backend.git(context.Background(), "cat-file", "-t", "missing")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 128 {
+		t.Errorf("Expected exit status 128, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected the stderr of git in the error, got %v", err)
+	}
+}
+
+// TestGitBackend tests the local git backend against a generated fixture repository
+func TestGitBackend(t *testing.T) {
+	f := newSimpleMergeFixture(t)
+	client, err := NewLocalClient(context.Background(), f.dir)
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+
+	testCases := []struct {
+		Name   string
+		Base   string
+		Head   string
+		SHA    string
+		Depth  int
+		Status Status
+	}{
+		{Name: "WalkToFeature", Base: "main", Head: "feature", SHA: f.sha("C"), Depth: 2, Status: StatusDiverged},
+		{Name: "WalkToMain", Base: "feature", Head: "main", SHA: f.sha("B"), Depth: 1, Status: StatusDiverged},
+		{Name: "FromMergeBase", Base: "A", Head: "feature", SHA: f.sha("C"), Depth: 2, Status: StatusAhead},
+		{Name: "Identical", Base: "main", Head: "main", SHA: "", Depth: 0, Status: StatusIdentical},
+		{Name: "UpToDate", Base: "feature", Head: "A", SHA: "", Depth: 0, Status: StatusUpToDate},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := client.GetMergeBaseNext(tc.Base, tc.Head, 1)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.SHA != tc.SHA {
+				t.Errorf("Expected SHA %s, got %s", tc.SHA, result.SHA)
+			}
+			if result.Depth != tc.Depth {
+				t.Errorf("Expected Depth %d, got %d", tc.Depth, result.Depth)
+			}
+			if result.Status != tc.Status {
+				t.Errorf("Expected Status %s, got %s", tc.Status, result.Status)
+			}
+		})
+	}
+
+	t.Run("FirstParentPathThroughMerge", func(t *testing.T) {
+		f.git("merge", "--quiet", "--no-ff", "-m", "M: Merge feature into main", "feature")
+		result, err := client.GetMergeBasePath("A", "main")
+		if err != nil {
+			t.Fatalf("GetMergeBasePath failed: %v", err)
+		}
+		expected := []string{f.sha("B"), f.sha("main")}
+		if len(result.Commits) != len(expected) {
+			t.Fatalf("Expected %d commits, got %d", len(expected), len(result.Commits))
+		}
+		for i, commit := range result.Commits {
+			if commit.GetSHA() != expected[i] {
+				t.Errorf("Expected path[%d] SHA %s, got %s", i, expected[i], commit.GetSHA())
+			}
+		}
+		if result.Commits[1].GetCommit().GetMessage() != "M: Merge feature into main" {
+			t.Errorf("Unexpected merge commit message: %q", result.Commits[1].GetCommit().GetMessage())
+		}
+	})
+
+	t.Run("UnknownRef", func(t *testing.T) {
+		if _, err := client.GetMergeBaseNext("main", "nonexistent-branch", 1); err == nil {
+			t.Errorf("Expected error but got none")
+		}
+	})
+}
@@ -0,0 +1,367 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/google/go-github/v88/github"
+)
+
+// comparePerPage is the page size used when paging through a comparison.
+// Without pagination the compare API truncates the commit list at 250 commits.
+const comparePerPage = 100
+
+// checkBranchPrefix is the prefix of the throwaway branches created by CheckMerge.
+const checkBranchPrefix = "merge-base-next/check/"
+
+// maxMergeBaseCandidates bounds the merge-base candidates ListMergeBases compares with each other, one request per pair.
+const maxMergeBaseCandidates = 8
+
+// GitHubBackend reads commits of a repository through the GitHub API.
+// Its methods return failed requests as APIError.
+type GitHubBackend struct {
+	client *github.Client
+	repo   repository.Repository
+}
+
+// NewGitHubBackend creates a backend for repo authenticated with the gh token for its host.
+func NewGitHubBackend(repo repository.Repository) (*GitHubBackend, error) {
+	return NewGitHubBackendWithAuth(repo, Auth{})
+}
+
+// NewGitHubBackendWithAuth creates a backend for repo authenticated as configured by auth.
+// Rate limited requests are retried with DefaultRetryPolicy.
+func NewGitHubBackendWithAuth(repo repository.Repository, auth Auth) (*GitHubBackend, error) {
+	return newGitHubBackend(repo, auth, nil, DefaultRetryPolicy)
+}
+
+// newGitHubBackend creates a backend for repo authenticated as configured by auth, sending requests with transport
+// and retrying rate limited requests with policy. A nil transport means http.DefaultTransport.
+func newGitHubBackend(repo repository.Repository, auth Auth, transport http.RoundTripper, policy RetryPolicy) (*GitHubBackend, error) {
+	httpClient, err := auth.httpClient(repo.Host, transport)
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Transport = newRetryTransport(httpClient.Transport, policy)
+	client, err := newGitHubClient(repo.Host, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubBackend{
+		client: client,
+		repo:   repo,
+	}, nil
+}
+
+// newGitHubClient creates a go-github client for the API of host that sends its requests with httpClient.
+// The API of github.com is api.github.com, and that of a GitHub Enterprise Server host is under /api/v3.
+func newGitHubClient(host string, httpClient *http.Client) (*github.Client, error) {
+	opts := []github.ClientOptionsFunc{github.WithHTTPClient(httpClient)}
+	if host != "" && host != "github.com" {
+		opts = append(opts, github.WithEnterpriseURLs(fmt.Sprintf("https://%s/api/v3/", host), fmt.Sprintf("https://%s/api/uploads/", host)))
+	}
+	return github.NewClient(opts...)
+}
+
+// CompareCommits compares base and head, paging through every commit of the comparison.
+// Refs of other repositories in the network are written owner:ref, or owner:repo:ref.
+func (b *GitHubBackend) CompareCommits(ctx context.Context, base string, head string) (*github.CommitsComparison, error) {
+	opts := &github.ListOptions{PerPage: comparePerPage}
+	var comparison *github.CommitsComparison
+	for {
+		page, resp, err := b.client.Repositories.CompareCommits(ctx, b.repo.Owner, b.repo.Name, compareRef(base), compareRef(head), opts)
+		if err != nil {
+			return nil, newAPIError(err)
+		}
+		if comparison == nil {
+			comparison = page
+		} else {
+			comparison.Commits = append(comparison.Commits, page.Commits...)
+		}
+		if resp.NextPage == 0 || len(page.Commits) == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return comparison, nil
+}
+
+// ListMergeBases lists every best common ancestor of base and head from comparison, the comparison of base and head.
+// The compare API reports only one merge-base, so the parents of the compared commits that base contains are the candidates.
+// Each other candidate is compared once with the reported merge-base, which also reads its commit, and the remaining ones once with each other;
+// those that are ancestors of another are dropped.
+// A truncated comparison, or one with more than maxMergeBaseCandidates candidates, fails with ErrMergeBasesUndetermined.
+func (b *GitHubBackend) ListMergeBases(ctx context.Context, base string, head string, comparison *github.CommitsComparison) ([]*github.RepositoryCommit, error) {
+	mergeBase := comparison.GetMergeBaseCommit()
+	if len(comparison.Commits) == 0 {
+		return []*github.RepositoryCommit{mergeBase}, nil
+	}
+	if len(comparison.Commits) < comparison.GetTotalCommits() {
+		return nil, fmt.Errorf("%w: the comparison of %s and %s is truncated", ErrMergeBasesUndetermined, base, head)
+	}
+
+	graph := newComparisonGraph(comparison)
+	candidates := []string{}
+	for _, commit := range comparison.Commits {
+		for _, parent := range commit.Parents {
+			sha := parent.GetSHA()
+			if _, ok := graph.Commit(sha); !ok && sha != mergeBase.GetSHA() && !slices.Contains(candidates, sha) {
+				candidates = append(candidates, sha)
+			}
+		}
+	}
+	if len(candidates) > maxMergeBaseCandidates {
+		return nil, fmt.Errorf("%w: %s and %s have %d other merge-base candidates to compare, more than %d", ErrMergeBasesUndetermined, base, head, len(candidates), maxMergeBaseCandidates)
+	}
+
+	others := []*github.RepositoryCommit{}
+	for _, candidate := range candidates {
+		page, err := b.compareStatus(ctx, candidate, mergeBase.GetSHA())
+		if err != nil {
+			return nil, err
+		}
+		if page.GetStatus() != "ahead" {
+			others = append(others, page.GetBaseCommit())
+		}
+	}
+	ancestors := map[string]bool{}
+	for i := range others {
+		for j := i + 1; j < len(others); j++ {
+			page, err := b.compareStatus(ctx, others[i].GetSHA(), others[j].GetSHA())
+			if err != nil {
+				return nil, err
+			}
+			switch page.GetStatus() {
+			case "ahead":
+				ancestors[others[i].GetSHA()] = true
+			case "behind":
+				ancestors[others[j].GetSHA()] = true
+			}
+		}
+	}
+	mergeBases := []*github.RepositoryCommit{mergeBase}
+	for _, commit := range others {
+		if !ancestors[commit.GetSHA()] {
+			mergeBases = append(mergeBases, commit)
+		}
+	}
+	return mergeBases, nil
+}
+
+// compareStatus compares base and head with a single page of commits, for the status and the base commit.
+func (b *GitHubBackend) compareStatus(ctx context.Context, base string, head string) (*github.CommitsComparison, error) {
+	page, _, err := b.client.Repositories.CompareCommits(ctx, b.repo.Owner, b.repo.Name, base, head, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	return page, nil
+}
+
+// GetCommitSHA1 resolves ref to a commit SHA.
+// A ref written owner:ref is resolved in the fork of owner, assumed to have the name of the repository,
+// and a ref written owner:repo:ref in that repository.
+func (b *GitHubBackend) GetCommitSHA1(ctx context.Context, ref string) (string, error) {
+	owner, repo, ref := b.refRepository(ref)
+	sha, _, err := b.client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		return "", newAPIError(err)
+	}
+	return sha, nil
+}
+
+// refRepository returns the repository ref belongs to and the ref within it.
+func (b *GitHubBackend) refRepository(ref string) (string, string, string) {
+	crossRef, ok := parseCrossRepositoryRef(ref)
+	if !ok {
+		return b.repo.Owner, b.repo.Name, ref
+	}
+	if crossRef.repo == "" {
+		return crossRef.owner, b.repo.Name, crossRef.ref
+	}
+	return crossRef.owner, crossRef.repo, crossRef.ref
+}
+
+// compareRef converts a ref of another repository in the network into the owner:ref syntax of the compare API,
+// which finds the repository of owner in the network by itself.
+func compareRef(ref string) string {
+	if crossRef, ok := parseCrossRepositoryRef(ref); ok {
+		return crossRef.owner + ":" + crossRef.ref
+	}
+	return ref
+}
+
+// Merge merges head into the base branch with the merges API.
+func (b *GitHubBackend) Merge(ctx context.Context, base string, head string, message string) (*github.RepositoryCommit, error) {
+	commit, _, err := b.client.Repositories.Merge(ctx, b.repo.Owner, b.repo.Name, &github.RepositoryMergeRequest{
+		Base:          github.Ptr(base),
+		Head:          github.Ptr(head),
+		CommitMessage: github.Ptr(message),
+	})
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	return commit, nil
+}
+
+// CreateBranch creates a branch pointing at sha.
+func (b *GitHubBackend) CreateBranch(ctx context.Context, branch string, sha string) error {
+	_, _, err := b.client.Git.CreateRef(ctx, b.repo.Owner, b.repo.Name, github.CreateRef{
+		Ref: "refs/heads/" + branch,
+		SHA: sha,
+	})
+	return newAPIError(err)
+}
+
+// DeleteBranch deletes a branch.
+func (b *GitHubBackend) DeleteBranch(ctx context.Context, branch string) error {
+	_, err := b.client.Git.DeleteRef(ctx, b.repo.Owner, b.repo.Name, "refs/heads/"+branch)
+	return newAPIError(err)
+}
+
+// CreatePullRequest opens a pull request.
+func (b *GitHubBackend) CreatePullRequest(ctx context.Context, pull *github.NewPullRequest) (*github.PullRequest, error) {
+	pr, _, err := b.client.PullRequests.Create(ctx, b.repo.Owner, b.repo.Name, pull)
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	return pr, nil
+}
+
+// RequestReviewers requests reviews on pull request number from users and teams.
+func (b *GitHubBackend) RequestReviewers(ctx context.Context, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error) {
+	pr, _, err := b.client.PullRequests.RequestReviewers(ctx, b.repo.Owner, b.repo.Name, number, reviewers)
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	return pr, nil
+}
+
+// AddAssignees assigns users to pull request number.
+func (b *GitHubBackend) AddAssignees(ctx context.Context, number int, assignees []string) error {
+	_, _, err := b.client.Issues.AddAssignees(ctx, b.repo.Owner, b.repo.Name, number, assignees)
+	return newAPIError(err)
+}
+
+// ListCommitFiles lists the files changed by the commit sha, paging through the files of the commit.
+func (b *GitHubBackend) ListCommitFiles(ctx context.Context, sha string) ([]*github.CommitFile, error) {
+	opts := &github.ListOptions{PerPage: comparePerPage}
+	files := []*github.CommitFile{}
+	for {
+		commit, resp, err := b.client.Repositories.GetCommit(ctx, b.repo.Owner, b.repo.Name, sha, opts)
+		if err != nil {
+			return nil, newAPIError(err)
+		}
+		files = append(files, commit.Files...)
+		if resp.NextPage == 0 || len(commit.Files) == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return files, nil
+}
+
+// GetFileContent returns the content of the file at path in ref, or nil when the file does not exist.
+func (b *GitHubBackend) GetFileContent(ctx context.Context, ref string, path string) ([]byte, error) {
+	owner, repo, ref := b.refRepository(ref)
+	file, _, resp, err := b.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	if file == nil {
+		// path is a directory
+		return nil, nil
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// ListPullRequestsWithCommit lists the pull requests associated with the commit sha with the commits-to-pulls API.
+func (b *GitHubBackend) ListPullRequestsWithCommit(ctx context.Context, sha string) ([]*github.PullRequest, error) {
+	pulls, _, err := b.client.PullRequests.ListPullRequestsWithCommit(ctx, b.repo.Owner, b.repo.Name, sha, nil)
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	return pulls, nil
+}
+
+// GetPullRequest returns pull request number.
+func (b *GitHubBackend) GetPullRequest(ctx context.Context, number int) (*github.PullRequest, error) {
+	pr, _, err := b.client.PullRequests.Get(ctx, b.repo.Owner, b.repo.Name, number)
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	return pr, nil
+}
+
+// CheckMerge test-merges head into a throwaway branch created at base, then deletes the branch.
+// The merges API does not report conflicting files, so on conflict the files changed on both sides
+// since the merge-base are reported instead.
+func (b *GitHubBackend) CheckMerge(ctx context.Context, base string, head string) (check *MergeCheck, err error) {
+	baseSHA, err := b.GetCommitSHA1(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.GetCommitSHA1(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	branch := fmt.Sprintf("%s%s-%d", checkBranchPrefix, headSHA, time.Now().UnixNano())
+	if err := b.CreateBranch(ctx, branch, baseSHA); err != nil {
+		return nil, fmt.Errorf("error creating test merge branch %s: %w", branch, err)
+	}
+	defer func() {
+		deleteErr := b.DeleteBranch(context.WithoutCancel(ctx), branch)
+		if deleteErr != nil && err == nil {
+			err = fmt.Errorf("error deleting test merge branch %s: %w", branch, deleteErr)
+		}
+	}()
+
+	_, err = b.Merge(ctx, branch, headSHA, "Test merge "+headSHA)
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusConflict {
+		files, err := b.overlappingFiles(ctx, baseSHA, headSHA)
+		if err != nil {
+			return nil, err
+		}
+		return &MergeCheck{Conflict: true, Files: files}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error test merging %s into %s: %w", headSHA, baseSHA, err)
+	}
+	return &MergeCheck{Conflict: false, Files: []string{}}, nil
+}
+
+// overlappingFiles returns the files changed both from the merge-base to base and from the merge-base to head.
+func (b *GitHubBackend) overlappingFiles(ctx context.Context, baseSHA string, headSHA string) ([]string, error) {
+	headSide, _, err := b.client.Repositories.CompareCommits(ctx, b.repo.Owner, b.repo.Name, baseSHA, headSHA, nil)
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	baseSide, _, err := b.client.Repositories.CompareCommits(ctx, b.repo.Owner, b.repo.Name, headSHA, baseSHA, nil)
+	if err != nil {
+		return nil, newAPIError(err)
+	}
+	changed := map[string]bool{}
+	for _, file := range baseSide.Files {
+		changed[file.GetFilename()] = true
+	}
+	files := []string{}
+	for _, file := range headSide.Files {
+		if changed[file.GetFilename()] {
+			files = append(files, file.GetFilename())
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
@@ -0,0 +1,30 @@
+package mergebasenext
+
+import (
+	"github.com/google/go-github/v88/github"
+)
+
+// CommitGraph looks up commits by SHA for the first-parent walk.
+// A commit that is not part of the graph ends the walk.
+type CommitGraph interface {
+	Commit(sha string) (*github.RepositoryCommit, bool)
+}
+
+// comparisonGraph is the CommitGraph made of the commits of a comparison, indexed by SHA.
+type comparisonGraph struct {
+	commits map[string]*github.RepositoryCommit
+}
+
+// newComparisonGraph indexes the commits of commitsComparison once so every lookup is O(1).
+func newComparisonGraph(commitsComparison *github.CommitsComparison) *comparisonGraph {
+	commits := make(map[string]*github.RepositoryCommit, len(commitsComparison.Commits))
+	for _, commit := range commitsComparison.Commits {
+		commits[commit.GetSHA()] = commit
+	}
+	return &comparisonGraph{commits: commits}
+}
+
+func (g *comparisonGraph) Commit(sha string) (*github.RepositoryCommit, bool) {
+	commit, ok := g.commits[sha]
+	return commit, ok
+}
@@ -0,0 +1,82 @@
+package mergebasenext
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// groupGraph has runs of commits by alternating authors on the feature branch
+var groupGraph = `
+main    -> b1
+feature -> c5
+
+c5 <- c4 @alice : C5: Alice again
+c4 <- c3 @bob   : C4: Bob's second change
+c3 <- c2 @bob   : C3: Bob's first change
+c2 <- c1 @alice : C2: Alice's second change
+c1 <- a1 @alice : C1: Alice's first change
+b1 <- a1 @carol : B: Main branch development
+a1              : A: Initial commit (merge-base)
+`
+
+// TestGetMergeBaseNextGroup tests grouping consecutive commits by the same author into one merge step
+func TestGetMergeBaseNextGroup(t *testing.T) {
+	client := newGraphClient(t, groupGraph)
+	testCases := []struct {
+		Name   string
+		Steps  int
+		From   string
+		To     string
+		Author string
+		Count  int
+		Depth  int
+	}{
+		{Name: "FirstRun", Steps: 1, From: "c1", To: "c2", Author: "alice", Count: 2, Depth: 4},
+		{Name: "MiddleOfRun", Steps: 2, From: "c2", To: "c2", Author: "alice", Count: 1, Depth: 4},
+		{Name: "SecondRun", Steps: 3, From: "c3", To: "c4", Author: "bob", Count: 2, Depth: 2},
+		{Name: "Head", Steps: 5, From: "c5", To: "c5", Author: "alice", Count: 1, Depth: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := client.GetMergeBaseNextGroup("main", "feature", tc.Steps)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNextGroup failed: %v", err)
+			}
+			if result.From != tc.From || result.To != tc.To || result.Author != tc.Author || result.Count != tc.Count || result.Depth != tc.Depth {
+				t.Errorf("Expected %s..%s by %s (count %d, depth %d), got %s..%s by %s (count %d, depth %d)",
+					tc.From, tc.To, tc.Author, tc.Count, tc.Depth, result.From, result.To, result.Author, result.Count, result.Depth)
+			}
+			if result.Commit.GetSHA() != tc.To {
+				t.Errorf("Expected commit %s, got %s", tc.To, result.Commit.GetSHA())
+			}
+			if result.Status != StatusDiverged {
+				t.Errorf("Expected Status %s, got %s", StatusDiverged, result.Status)
+			}
+		})
+	}
+
+	t.Run("NothingToMerge", func(t *testing.T) {
+		result, err := client.GetMergeBaseNextGroup("feature", "a1", 1)
+		if err != nil {
+			t.Fatalf("GetMergeBaseNextGroup failed: %v", err)
+		}
+		if result.Commit != nil || result.Count != 0 || result.Status != StatusUpToDate {
+			t.Errorf("Expected an empty group, got %+v", result)
+		}
+	})
+}
+
+// TestCommitAuthor tests identifying authors without a GitHub login by git name and email
+func TestCommitAuthor(t *testing.T) {
+	commit := &github.RepositoryCommit{
+		Commit: &github.Commit{Author: &github.CommitAuthor{Name: github.Ptr("Alice"), Email: github.Ptr("alice@example.com")}},
+	}
+	if author := commitAuthor(commit); author != "Alice <alice@example.com>" {
+		t.Errorf("Expected git author identity, got %s", author)
+	}
+	commit.Author = &github.User{Login: github.Ptr("alice")}
+	if author := commitAuthor(commit); author != "alice" {
+		t.Errorf("Expected GitHub login, got %s", author)
+	}
+}
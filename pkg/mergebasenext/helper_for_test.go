@@ -2,33 +2,112 @@ package mergebasenext
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+	"github.com/google/go-github/v88/github"
 )
 
 var testRepository = "srz-zumix/gh-merge-base-next"
 
+// liveTestEnv runs the scenario tests against the testdata branches on GitHub instead of the offline fixtures
+const liveTestEnv = "GH_MERGE_BASE_NEXT_LIVE_TEST"
+
 var testClient *Client
 
 func getTestClient(t *testing.T) *Client {
 	if testClient != nil {
 		return testClient
 	}
-	c, err := NewClient(context.Background(), testRepository)
-	if err != nil {
-		t.Fatalf("Failed to create mergebasenext client: %v", err)
+	if os.Getenv(liveTestEnv) != "" {
+		c, err := NewClient(context.Background(), testRepository)
+		if err != nil {
+			t.Fatalf("Failed to create mergebasenext client: %v", err)
+		}
+		testClient = c
+		return testClient
 	}
-	testClient = c
+	testClient = newGraphClient(t, strings.Join([]string{
+		simpleMergeGraph,
+		complexMergeGraph,
+		crossMergeGraph,
+		multipleMergeBaseGraph,
+		fastForwardGraph,
+		errorCasesGraph,
+	}, "\n"))
 	return testClient
 }
 
+// newGraphClient returns a client backed by an in-memory commit graph
+func newGraphClient(t *testing.T, graph string) *Client {
+	backend, err := NewMemoryBackend(graph)
+	if err != nil {
+		t.Fatalf("Failed to parse commit graph: %v", err)
+	}
+	return NewClientWithBackend(context.Background(), backend)
+}
+
+// newTestServerClient returns a client whose API requests are served by handler
+func newTestServerClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := github.NewClient(github.WithEnterpriseURLs(server.URL, server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create GitHub client: %v", err)
+	}
+	return NewClientWithBackend(context.Background(), &GitHubBackend{
+		client: client,
+		repo:   repository.Repository{Host: "github.com", Owner: "srz-zumix", Name: "gh-merge-base-next"},
+	})
+}
+
+// serverTransport sends every request to server, keeping its path, as if server were the host of the request URL
+type serverTransport struct {
+	server *httptest.Server
+}
+
+func (t serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return t.server.Client().Transport.RoundTrip(req)
+}
+
+// newTestServerTransport returns a transport whose requests to any host are served by handler
+func newTestServerTransport(t *testing.T, handler http.Handler) http.RoundTripper {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return serverTransport{server: server}
+}
+
 type TestCase struct {
-	Name  string
-	Base  string
-	Head  string
-	SHA   string
-	Depth int
-	Desc  string
+	Name   string
+	Base   string
+	Head   string
+	Steps  int
+	SHA    string
+	Depth  int
+	Status Status
+	Desc   string
+}
+
+type PathTestCase struct {
+	Name   string
+	Base   string
+	Head   string
+	SHAs   []string
+	Status Status
+	Desc   string
 }
 
 type ErrorTestCase struct {
@@ -41,7 +120,7 @@ type ErrorTestCase struct {
 
 func (tc *TestCase) Run(t *testing.T) {
 	client := getTestClient(t)
-	result, err := client.GetMergeBaseNext(tc.Base, tc.Head)
+	result, err := client.GetMergeBaseNext(tc.Base, tc.Head, tc.Steps)
 	if err != nil {
 		t.Fatalf("GetMergeBaseNext failed: %v", err)
 	}
@@ -51,11 +130,32 @@ func (tc *TestCase) Run(t *testing.T) {
 	if result.Depth != tc.Depth {
 		t.Errorf("Expected Depth %d, got %d", tc.Depth, result.Depth)
 	}
+	if tc.Status != "" && result.Status != tc.Status {
+		t.Errorf("Expected Status %s, got %s", tc.Status, result.Status)
+	}
+}
+
+func (ptc *PathTestCase) Run(t *testing.T) {
+	client := getTestClient(t)
+	result, err := client.GetMergeBasePath(ptc.Base, ptc.Head)
+	if err != nil {
+		t.Fatalf("GetMergeBasePath failed: %v", err)
+	}
+	shas := make([]string, 0, len(result.Commits))
+	for _, commit := range result.Commits {
+		shas = append(shas, commit.GetSHA())
+	}
+	if !slices.Equal(shas, ptc.SHAs) {
+		t.Errorf("Expected SHAs %v, got %v", ptc.SHAs, shas)
+	}
+	if ptc.Status != "" && result.Status != ptc.Status {
+		t.Errorf("Expected Status %s, got %s", ptc.Status, result.Status)
+	}
 }
 
 func (etc *ErrorTestCase) Run(t *testing.T) {
 	client := getTestClient(t)
-	_, err := client.GetMergeBaseNext(etc.Base, etc.Head)
+	_, err := client.GetMergeBaseNext(etc.Base, etc.Head, 1)
 	if err == nil {
 		t.Fatalf("Expected error but got none")
 	}
@@ -0,0 +1,80 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestMainlineParentIndex tests walking along the second parent of merge commits
+func TestMainlineParentIndex(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Graph string
+		Base  string
+		Head  string
+		SHA   string
+		Depth int
+		Desc  string
+	}{
+		{
+			Name:  "ComplexMergeSecondParent",
+			Graph: complexMergeGraph,
+			Base:  "00506e8",
+			Head:  "testdata/complex-merge/main",
+			SHA:   "75ff397267f653296190ade85624b664920353b4",
+			Depth: 3,
+			Desc:  "Walk G -> E -> B (second parent of E) and return B: Feature1 development",
+		},
+		{
+			Name:  "CrossMergeSecondParent",
+			Graph: crossMergeGraph,
+			Base:  "9b93392",
+			Head:  "testdata/cross-merge/feature1",
+			SHA:   "74ec9ee6dd54286b15638541a9590d770cf039f2",
+			Depth: 4,
+			Desc:  "Walk M2 -> F2 -> G -> B following the second parent of both merges",
+		},
+		{
+			Name:  "LinearHistoryIgnoresParentIndex",
+			Graph: fastForwardGraph,
+			Base:  "testdata/fast-forward/main",
+			Head:  "testdata/fast-forward/feature",
+			SHA:   "463ec54c18fd7544fcf276aec8a3c1e185b61b6c",
+			Depth: 3,
+			Desc:  "Commits with a single parent follow it regardless of the parent index",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			backend, err := NewMemoryBackend(tc.Graph)
+			if err != nil {
+				t.Fatalf("NewMemoryBackend failed: %v", err)
+			}
+			client := NewClientWithBackend(context.Background(), backend, WithParentIndex(1))
+			result, err := client.GetMergeBaseNext(tc.Base, tc.Head, 1)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.SHA != tc.SHA {
+				t.Errorf("Expected SHA %s, got %s", tc.SHA, result.SHA)
+			}
+			if result.Depth != tc.Depth {
+				t.Errorf("Expected Depth %d, got %d", tc.Depth, result.Depth)
+			}
+		})
+	}
+}
+
+// TestMainlineParentIndexOutOfRange tests that a merge commit without the requested parent is an error
+func TestMainlineParentIndexOutOfRange(t *testing.T) {
+	backend, err := NewMemoryBackend(complexMergeGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	client := NewClientWithBackend(context.Background(), backend, WithParentIndex(2))
+	_, err = client.GetMergeBaseNext("00506e8", "testdata/complex-merge/main", 1)
+	if !errors.Is(err, ErrParentIndexOutOfRange) {
+		t.Errorf("Expected ErrParentIndexOutOfRange, got %v", err)
+	}
+}
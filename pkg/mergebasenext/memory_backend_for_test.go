@@ -0,0 +1,542 @@
+package mergebasenext
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// minAbbrevLength is the shortest SHA prefix MemoryBackend resolves to a commit.
+const minAbbrevLength = 4
+
+// MemoryBackend is an in-memory Backend built from a commit graph description, for tests.
+// It computes comparisons the way the GitHub compare API does, so walks can be tested without network access.
+// A ref or comparison that does not exist fails with ErrNotFound, like a 404 response of the GitHub API.
+//
+// The graph is described one entry per line, newest first like `git log --graph`:
+//
+//	# comments start with '#'
+//	<ref> -> <sha>
+//	<sha> [<- <parent> [<parent>...]] [@<author>] [+<file>...] [#<pull request>] [: <message>]
+//
+// SHAs may be abbreviated wherever a commit is referenced, as long as the prefix is unique.
+// The author is a GitHub login and is also used as the git author name.
+// Files are the paths the commit changes; CheckMerge treats files changed on both sides as conflicts.
+// The pull request number associates the commit with a merged pull request, titled after its newest commit.
+//
+// Writes (merges, branches and pull requests) are applied to the in-memory graph.
+type MemoryBackend struct {
+	mu      sync.RWMutex
+	commits map[string]*github.RepositoryCommit
+	order   []string
+	refs    map[string]string
+	pulls   map[int]*github.PullRequest
+	// commitPulls maps commit SHAs to the numbers of the pull requests they belong to
+	commitPulls map[string][]int
+	// files holds file contents by path, the same for every ref
+	files map[string][]byte
+}
+
+// NewMemoryBackend parses graph and returns a backend serving its commits and refs.
+func NewMemoryBackend(graph string) (*MemoryBackend, error) {
+	b := &MemoryBackend{
+		commits:     map[string]*github.RepositoryCommit{},
+		refs:        map[string]string{},
+		pulls:       map[int]*github.PullRequest{},
+		commitPulls: map[string][]int{},
+		files:       map[string][]byte{},
+	}
+	parents := map[string][]string{}
+	refTargets := map[string]string{}
+	for i, line := range strings.Split(graph, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		spec, message, hasMessage := strings.Cut(line, ":")
+		if ref, target, isRef := strings.Cut(spec, "->"); isRef && !hasMessage {
+			ref, target = strings.TrimSpace(ref), strings.TrimSpace(target)
+			if ref == "" || target == "" {
+				return nil, fmt.Errorf("line %d: invalid ref %q", i+1, line)
+			}
+			refTargets[ref] = target
+			continue
+		}
+		shaPart, parentPart, _ := strings.Cut(spec, "<-")
+		fields := strings.Fields(shaPart)
+		if len(fields) == 0 || slices.ContainsFunc(fields[1:], func(field string) bool { return !strings.ContainsAny(field[:1], "@+#") }) {
+			return nil, fmt.Errorf("line %d: invalid commit %q", i+1, line)
+		}
+		sha := fields[0]
+		if _, ok := b.commits[sha]; ok {
+			return nil, fmt.Errorf("line %d: duplicate commit %s", i+1, sha)
+		}
+		commit := &github.RepositoryCommit{
+			SHA: github.Ptr(sha),
+			Commit: &github.Commit{
+				SHA:     github.Ptr(sha),
+				Message: github.Ptr(strings.TrimSpace(message)),
+			},
+		}
+		for _, field := range append(fields[1:], strings.Fields(parentPart)...) {
+			if login, isAuthor := strings.CutPrefix(field, "@"); isAuthor {
+				commit.Author = &github.User{Login: github.Ptr(login)}
+				commit.Commit.Author = &github.CommitAuthor{Name: github.Ptr(login)}
+				continue
+			}
+			if file, isFile := strings.CutPrefix(field, "+"); isFile {
+				commit.Files = append(commit.Files, &github.CommitFile{Filename: github.Ptr(file), Status: github.Ptr("modified")})
+				continue
+			}
+			if number, isPull := strings.CutPrefix(field, "#"); isPull {
+				n, err := strconv.Atoi(number)
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("line %d: invalid pull request %q", i+1, field)
+				}
+				b.addMergedPullRequest(n, commit)
+				continue
+			}
+			parents[sha] = append(parents[sha], field)
+		}
+		b.commits[sha] = commit
+		b.order = append(b.order, sha)
+	}
+
+	for _, sha := range b.order {
+		commit := b.commits[sha]
+		commit.Parents = []*github.Commit{}
+		for _, parent := range parents[sha] {
+			parentSHA, ok := b.lookupCommit(parent)
+			if !ok {
+				return nil, fmt.Errorf("commit %s: unknown parent %s", sha, parent)
+			}
+			commit.Parents = append(commit.Parents, &github.Commit{SHA: github.Ptr(parentSHA)})
+		}
+	}
+	for ref, target := range refTargets {
+		sha, ok := b.lookupCommit(target)
+		if !ok {
+			return nil, fmt.Errorf("ref %s: unknown commit %s", ref, target)
+		}
+		b.refs[ref] = sha
+	}
+	return b, nil
+}
+
+// Commit returns the commit with the given full SHA.
+func (b *MemoryBackend) Commit(sha string) (*github.RepositoryCommit, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	commit, ok := b.commits[sha]
+	return commit, ok
+}
+
+// CompareCommits compares base and head like the GitHub compare API (base...head).
+func (b *MemoryBackend) CompareCommits(ctx context.Context, base string, head string) (*github.CommitsComparison, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	baseSHA, err := b.resolve(base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.resolve(head)
+	if err != nil {
+		return nil, err
+	}
+
+	baseAncestors := b.ancestors(baseSHA)
+	headAncestors := b.ancestors(headSHA)
+	mergeBases := b.mergeBases(baseAncestors, headAncestors)
+	if len(mergeBases) == 0 {
+		return nil, fmt.Errorf("%w: no common ancestor between %s and %s", ErrNotFound, base, head)
+	}
+
+	commits := []*github.RepositoryCommit{}
+	for _, sha := range b.topoOrder(headSHA) {
+		if !baseAncestors[sha] {
+			commits = append(commits, b.commits[sha])
+		}
+	}
+	behindBy := 0
+	for sha := range baseAncestors {
+		if !headAncestors[sha] {
+			behindBy++
+		}
+	}
+
+	return &github.CommitsComparison{
+		BaseCommit:      b.commits[baseSHA],
+		MergeBaseCommit: b.commits[mergeBases[0]],
+		Status:          github.Ptr(compareStatus(len(commits), behindBy)),
+		AheadBy:         github.Ptr(len(commits)),
+		BehindBy:        github.Ptr(behindBy),
+		TotalCommits:    github.Ptr(len(commits)),
+		Commits:         commits,
+	}, nil
+}
+
+// ListMergeBases lists every best common ancestor of base and head.
+func (b *MemoryBackend) ListMergeBases(ctx context.Context, base string, head string, comparison *github.CommitsComparison) ([]*github.RepositoryCommit, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	baseSHA, err := b.resolve(base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.resolve(head)
+	if err != nil {
+		return nil, err
+	}
+	mergeBases := b.mergeBases(b.ancestors(baseSHA), b.ancestors(headSHA))
+	if len(mergeBases) == 0 {
+		return nil, fmt.Errorf("%w: no common ancestor between %s and %s", ErrNotFound, base, head)
+	}
+	commits := []*github.RepositoryCommit{}
+	for _, sha := range mergeBases {
+		commits = append(commits, b.commits[sha])
+	}
+	return commits, nil
+}
+
+// GetCommitSHA1 resolves a ref name or a possibly abbreviated SHA to a commit SHA.
+func (b *MemoryBackend) GetCommitSHA1(ctx context.Context, ref string) (string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.resolve(ref)
+}
+
+// Merge creates a merge commit of head on the base branch, or returns nil when head is already merged.
+func (b *MemoryBackend) Merge(ctx context.Context, base string, head string, message string) (*github.RepositoryCommit, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	baseSHA, ok := b.refs[base]
+	if !ok {
+		return nil, fmt.Errorf("%w: no branch %s", ErrNotFound, base)
+	}
+	headSHA, err := b.resolve(head)
+	if err != nil {
+		return nil, err
+	}
+	if b.ancestors(baseSHA)[headSHA] {
+		return nil, nil
+	}
+	sha := fmt.Sprintf("%x", sha1.Sum([]byte(baseSHA+headSHA+message)))
+	commit := &github.RepositoryCommit{
+		SHA: github.Ptr(sha),
+		Commit: &github.Commit{
+			SHA:     github.Ptr(sha),
+			Message: github.Ptr(message),
+		},
+		Parents: []*github.Commit{{SHA: github.Ptr(baseSHA)}, {SHA: github.Ptr(headSHA)}},
+	}
+	b.commits[sha] = commit
+	b.order = append([]string{sha}, b.order...)
+	b.refs[base] = sha
+	return commit, nil
+}
+
+// CreateBranch points a new branch at sha.
+func (b *MemoryBackend) CreateBranch(ctx context.Context, branch string, sha string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.refs[branch]; ok {
+		return fmt.Errorf("422 Reference already exists: %s", branch)
+	}
+	if _, ok := b.commits[sha]; !ok {
+		return fmt.Errorf("%w: no commit %s", ErrNotFound, sha)
+	}
+	b.refs[branch] = sha
+	return nil
+}
+
+// DeleteBranch removes a branch.
+func (b *MemoryBackend) DeleteBranch(ctx context.Context, branch string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.refs[branch]; !ok {
+		return fmt.Errorf("%w: no branch %s", ErrNotFound, branch)
+	}
+	delete(b.refs, branch)
+	return nil
+}
+
+// CreatePullRequest records a pull request between two existing branches.
+func (b *MemoryBackend) CreatePullRequest(ctx context.Context, pull *github.NewPullRequest) (*github.PullRequest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	baseSHA, ok := b.refs[pull.GetBase()]
+	if !ok {
+		return nil, fmt.Errorf("%w: no branch %s", ErrNotFound, pull.GetBase())
+	}
+	headSHA, ok := b.refs[pull.GetHead()]
+	if !ok {
+		return nil, fmt.Errorf("%w: no branch %s", ErrNotFound, pull.GetHead())
+	}
+	pr := &github.PullRequest{
+		Number: github.Ptr(b.nextPullRequestNumber()),
+		State:  github.Ptr("open"),
+		Title:  pull.Title,
+		Body:   pull.Body,
+		Base:   &github.PullRequestBranch{Ref: pull.Base, SHA: github.Ptr(baseSHA)},
+		Head:   &github.PullRequestBranch{Ref: pull.Head, SHA: github.Ptr(headSHA)},
+	}
+	b.pulls[pr.GetNumber()] = pr
+	return pr, nil
+}
+
+// GetPullRequest returns pull request number.
+func (b *MemoryBackend) GetPullRequest(ctx context.Context, number int) (*github.PullRequest, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.pullRequest(number)
+}
+
+// SetFileContent stores the content of the file at path, which GetFileContent returns for every ref.
+func (b *MemoryBackend) SetFileContent(path string, content []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.files[path] = content
+}
+
+// GetFileContent returns the content stored with SetFileContent, or nil when there is none.
+func (b *MemoryBackend) GetFileContent(ctx context.Context, ref string, path string) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if _, err := b.resolve(ref); err != nil {
+		return nil, err
+	}
+	return b.files[path], nil
+}
+
+// ListCommitFiles lists the files the graph description gives for the commit sha.
+func (b *MemoryBackend) ListCommitFiles(ctx context.Context, sha string) ([]*github.CommitFile, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	commit, ok := b.commits[sha]
+	if !ok {
+		return nil, fmt.Errorf("%w: no commit %s", ErrNotFound, sha)
+	}
+	return slices.Clone(commit.Files), nil
+}
+
+// CheckMerge reports the files changed on both sides since the merge-base of base and head as conflicts.
+func (b *MemoryBackend) CheckMerge(ctx context.Context, base string, head string) (*MergeCheck, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	baseSHA, err := b.resolve(base)
+	if err != nil {
+		return nil, err
+	}
+	headSHA, err := b.resolve(head)
+	if err != nil {
+		return nil, err
+	}
+	baseAncestors := b.ancestors(baseSHA)
+	headAncestors := b.ancestors(headSHA)
+	mergeBases := b.mergeBases(baseAncestors, headAncestors)
+	if len(mergeBases) == 0 {
+		return nil, fmt.Errorf("%w: no common ancestor between %s and %s", ErrNotFound, base, head)
+	}
+	mergeBaseAncestors := b.ancestors(mergeBases[0])
+	baseFiles := b.changedFiles(baseAncestors, mergeBaseAncestors)
+	files := []string{}
+	for _, file := range b.changedFiles(headAncestors, mergeBaseAncestors) {
+		if slices.Contains(baseFiles, file) {
+			files = append(files, file)
+		}
+	}
+	return &MergeCheck{
+		Conflict: len(files) > 0,
+		Files:    files,
+	}, nil
+}
+
+// changedFiles returns the sorted files changed by the commits in reachable but not in excluded.
+func (b *MemoryBackend) changedFiles(reachable map[string]bool, excluded map[string]bool) []string {
+	files := []string{}
+	for sha := range reachable {
+		if excluded[sha] {
+			continue
+		}
+		for _, file := range b.commits[sha].Files {
+			if !slices.Contains(files, file.GetFilename()) {
+				files = append(files, file.GetFilename())
+			}
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// RequestReviewers records requested reviewers on a pull request created by CreatePullRequest.
+func (b *MemoryBackend) RequestReviewers(ctx context.Context, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pr, err := b.pullRequest(number)
+	if err != nil {
+		return nil, err
+	}
+	for _, login := range reviewers.Reviewers {
+		pr.RequestedReviewers = append(pr.RequestedReviewers, &github.User{Login: github.Ptr(login)})
+	}
+	for _, slug := range reviewers.TeamReviewers {
+		pr.RequestedTeams = append(pr.RequestedTeams, &github.Team{Slug: github.Ptr(slug)})
+	}
+	return pr, nil
+}
+
+// AddAssignees records assignees on a pull request created by CreatePullRequest.
+func (b *MemoryBackend) AddAssignees(ctx context.Context, number int, assignees []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pr, err := b.pullRequest(number)
+	if err != nil {
+		return err
+	}
+	for _, login := range assignees {
+		pr.Assignees = append(pr.Assignees, &github.User{Login: github.Ptr(login)})
+	}
+	return nil
+}
+
+func (b *MemoryBackend) pullRequest(number int) (*github.PullRequest, error) {
+	pr, ok := b.pulls[number]
+	if !ok {
+		return nil, fmt.Errorf("%w: no pull request #%d", ErrNotFound, number)
+	}
+	return pr, nil
+}
+
+func (b *MemoryBackend) nextPullRequestNumber() int {
+	number := 1
+	for n := range b.pulls {
+		number = max(number, n+1)
+	}
+	return number
+}
+
+// addMergedPullRequest associates commit with the merged pull request number, creating it on first use.
+// Commits are parsed newest first, so the first commit seen is the merge commit and names the pull request.
+func (b *MemoryBackend) addMergedPullRequest(number int, commit *github.RepositoryCommit) {
+	if _, ok := b.pulls[number]; !ok {
+		title, _, _ := strings.Cut(commit.GetCommit().GetMessage(), "\n")
+		b.pulls[number] = &github.PullRequest{
+			Number:         github.Ptr(number),
+			State:          github.Ptr("closed"),
+			Title:          github.Ptr(title),
+			User:           commit.Author,
+			Merged:         github.Ptr(true),
+			MergeCommitSHA: commit.SHA,
+		}
+	}
+	b.commitPulls[commit.GetSHA()] = append(b.commitPulls[commit.GetSHA()], number)
+}
+
+// ListPullRequestsWithCommit lists the pull requests the graph description associates with the commit sha.
+func (b *MemoryBackend) ListPullRequestsWithCommit(ctx context.Context, sha string) ([]*github.PullRequest, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	pulls := []*github.PullRequest{}
+	for _, number := range b.commitPulls[sha] {
+		pulls = append(pulls, b.pulls[number])
+	}
+	return pulls, nil
+}
+
+// resolve resolves a ref name or a possibly abbreviated SHA to a commit SHA.
+func (b *MemoryBackend) resolve(ref string) (string, error) {
+	if sha, ok := b.refs[ref]; ok {
+		return sha, nil
+	}
+	if sha, ok := b.lookupCommit(ref); ok {
+		return sha, nil
+	}
+	return "", fmt.Errorf("%w: no commit found for %s", ErrNotFound, ref)
+}
+
+// lookupCommit finds the commit whose SHA is sha or uniquely starts with it.
+func (b *MemoryBackend) lookupCommit(sha string) (string, bool) {
+	if _, ok := b.commits[sha]; ok {
+		return sha, true
+	}
+	if len(sha) < minAbbrevLength {
+		return "", false
+	}
+	found := ""
+	for _, candidate := range b.order {
+		if strings.HasPrefix(candidate, sha) {
+			if found != "" {
+				return "", false
+			}
+			found = candidate
+		}
+	}
+	return found, found != ""
+}
+
+// ancestors returns the set of commits reachable from sha, including sha itself.
+func (b *MemoryBackend) ancestors(sha string) map[string]bool {
+	reachable := map[string]bool{}
+	stack := []string{sha}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if reachable[current] {
+			continue
+		}
+		reachable[current] = true
+		for _, parent := range b.commits[current].Parents {
+			stack = append(stack, parent.GetSHA())
+		}
+	}
+	return reachable
+}
+
+// mergeBases returns the best common ancestors of two ancestor sets in graph order.
+func (b *MemoryBackend) mergeBases(baseAncestors map[string]bool, headAncestors map[string]bool) []string {
+	common := []string{}
+	for _, sha := range b.order {
+		if baseAncestors[sha] && headAncestors[sha] {
+			common = append(common, sha)
+		}
+	}
+	best := []string{}
+	for _, candidate := range common {
+		dominated := false
+		for _, other := range common {
+			if other != candidate && b.ancestors(other)[candidate] {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			best = append(best, candidate)
+		}
+	}
+	return best
+}
+
+// topoOrder returns the commits reachable from sha with every parent before its children.
+func (b *MemoryBackend) topoOrder(sha string) []string {
+	visited := map[string]bool{}
+	order := []string{}
+	var visit func(string)
+	visit = func(current string) {
+		if visited[current] {
+			return
+		}
+		visited[current] = true
+		for _, parent := range b.commits[current].Parents {
+			visit(parent.GetSHA())
+		}
+		order = append(order, current)
+	}
+	visit(sha)
+	return order
+}
@@ -0,0 +1,52 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestMemoryBackendGraphErrors tests that malformed commit graph descriptions are rejected
+func TestMemoryBackendGraphErrors(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Graph string
+		Error string
+	}{
+		{Name: "UnknownParent", Graph: "b1 <- a1 : B", Error: "unknown parent a1"},
+		{Name: "UnknownRefTarget", Graph: "a1 : A\nmain -> b1", Error: "unknown commit b1"},
+		{Name: "DuplicateCommit", Graph: "a1 : A\na1 : A again", Error: "duplicate commit a1"},
+		{Name: "InvalidCommit", Graph: "a1 b1 : A", Error: "invalid commit"},
+		{Name: "AmbiguousParent", Graph: "abcd1 : A\nabcd2 : B\nc1 <- abcd : C", Error: "unknown parent abcd"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := NewMemoryBackend(tc.Graph)
+			if err == nil {
+				t.Fatalf("Expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tc.Error) {
+				t.Errorf("Expected error to contain '%s', got '%s'", tc.Error, err.Error())
+			}
+		})
+	}
+}
+
+// TestMemoryBackendUnrelatedHistories tests that comparing histories without a common ancestor fails like the API
+func TestMemoryBackendUnrelatedHistories(t *testing.T) {
+	backend, err := NewMemoryBackend(`
+main   -> 6ec9889
+orphan -> 9f0e1d2
+
+9f0e1d2 : Orphan commit
+6ec9889 : Initial commit
+`)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	_, err = backend.CompareCommits(context.Background(), "main", "orphan")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
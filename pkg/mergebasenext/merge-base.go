@@ -0,0 +1,95 @@
+package mergebasenext
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// ErrMultipleMergeBases is returned with MergeBaseReportFail when base and head have more than one merge-base.
+var ErrMultipleMergeBases = errors.New("multiple merge-bases")
+
+// ErrMergeBasesUndetermined is returned by a MergeBaseLister that cannot list every merge-base, such as from a truncated comparison.
+// MergeBaseReportNewest and MergeBaseReportFail fail with it rather than report the only merge-base the comparison knows.
+var ErrMergeBasesUndetermined = errors.New("merge-bases cannot be determined")
+
+// MergeBaseReport selects which merge-base is reported for criss-cross histories, where base and head have more than one.
+// It only changes the reported mergeBase, or makes such histories an error: the next commit is the same with every report,
+// because the walk stops at the first commit base already contains, whichever merge-base it is.
+type MergeBaseReport string
+
+const (
+	// MergeBaseReportGitHub reports the merge-base the backend comparison reports, like the GitHub compare API.
+	MergeBaseReportGitHub MergeBaseReport = "github"
+	// MergeBaseReportNewest reports the merge-base with the newest committer date.
+	MergeBaseReportNewest MergeBaseReport = "newest"
+	// MergeBaseReportFail fails with ErrMultipleMergeBases.
+	MergeBaseReportFail MergeBaseReport = "fail"
+)
+
+// MergeBaseReports lists the supported merge-base reports.
+var MergeBaseReports = []string{
+	string(MergeBaseReportGitHub),
+	string(MergeBaseReportNewest),
+	string(MergeBaseReportFail),
+}
+
+// listMergeBases lists every merge-base candidate of base and head, starting with the one comparison reports.
+// With a backend that cannot list merge-bases, the reported merge-base is the only candidate,
+// as it is with MergeBaseReportGitHub when the backend cannot list every merge-base.
+func (c *Client) listMergeBases(base string, head string, comparison *github.CommitsComparison) ([]*github.RepositoryCommit, error) {
+	mergeBases := []*github.RepositoryCommit{comparison.GetMergeBaseCommit()}
+	lister, ok := c.backend.(MergeBaseLister)
+	if !ok {
+		return mergeBases, nil
+	}
+	commits, err := lister.ListMergeBases(c.ctx, base, head, comparison)
+	if errors.Is(err, ErrMergeBasesUndetermined) && (c.mergeBaseReport == "" || c.mergeBaseReport == MergeBaseReportGitHub) {
+		return mergeBases, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing merge-bases of %s and %s: %w", base, head, err)
+	}
+	for _, commit := range commits {
+		if !slices.ContainsFunc(mergeBases, func(mergeBase *github.RepositoryCommit) bool { return mergeBase.GetSHA() == commit.GetSHA() }) {
+			mergeBases = append(mergeBases, commit)
+		}
+	}
+	return mergeBases, nil
+}
+
+// selectMergeBase applies the merge-base report of the client to the merge-base candidates,
+// returning them with the reported merge-base moved to the front.
+func (c *Client) selectMergeBase(base string, head string, mergeBases []*github.RepositoryCommit) ([]*github.RepositoryCommit, error) {
+	switch c.mergeBaseReport {
+	case "", MergeBaseReportGitHub:
+		return mergeBases, nil
+	case MergeBaseReportFail:
+		if len(mergeBases) > 1 {
+			return nil, fmt.Errorf("%w: %s and %s have %d merge-bases: %s", ErrMultipleMergeBases, base, head, len(mergeBases), strings.Join(commitSHAs(mergeBases), ", "))
+		}
+		return mergeBases, nil
+	case MergeBaseReportNewest:
+		index := 0
+		for i, commit := range mergeBases {
+			if commit.GetCommit().GetCommitter().GetDate().After(mergeBases[index].GetCommit().GetCommitter().GetDate().Time) {
+				index = i
+			}
+		}
+		return slices.Concat(mergeBases[index:index+1], mergeBases[:index], mergeBases[index+1:]), nil
+	default:
+		return nil, fmt.Errorf("unknown merge-base report %q", c.mergeBaseReport)
+	}
+}
+
+// commitSHAs returns the SHAs of commits.
+func commitSHAs(commits []*github.RepositoryCommit) []string {
+	shas := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		shas = append(shas, commit.GetSHA())
+	}
+	return shas
+}
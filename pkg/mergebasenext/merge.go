@@ -0,0 +1,176 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// ErrMergeUnsupported is returned when the client backend cannot write to the repository.
+var ErrMergeUnsupported = errors.New("backend does not support merging")
+
+// MergeMethod selects how the next commit is brought into the base branch.
+type MergeMethod string
+
+const (
+	// MergeMethodMerge merges the next commit into the base branch with the merges API.
+	MergeMethodMerge MergeMethod = "merge"
+	// MergeMethodPullRequest opens a pull request from a temporary branch pointed at the next commit.
+	MergeMethodPullRequest MergeMethod = "pull-request"
+)
+
+// MergeMethods lists the supported merge methods.
+var MergeMethods = []string{
+	string(MergeMethodMerge),
+	string(MergeMethodPullRequest),
+}
+
+const (
+	// DefaultMergeMessageTemplate is the default template of the merge commit message and pull request title and body.
+	DefaultMergeMessageTemplate = "Merge {{.ShortSHA}} into {{.Base}}\n\n{{.Subject}}"
+	// DefaultBranchTemplate is the default template of the temporary branch name for pull requests.
+	DefaultBranchTemplate = "merge-base-next/{{.Base}}/{{.ShortSHA}}"
+)
+
+// MergeOptions configures Client.Merge.
+type MergeOptions struct {
+	Method          MergeMethod
+	Steps           int
+	DryRun          bool
+	MessageTemplate string
+	BranchTemplate  string
+}
+
+// MergeMessageData is the data available to the message and branch templates.
+type MergeMessageData struct {
+	Base     string
+	Head     string
+	SHA      string
+	ShortSHA string
+	Subject  string
+	Message  string
+	Author   string
+	Depth    int
+}
+
+// MergeResult describes what Client.Merge created, or would create in a dry run.
+type MergeResult struct {
+	Method      MergeMethod              `json:"method"`
+	DryRun      bool                     `json:"dryRun"`
+	Base        string                   `json:"base"`
+	Head        string                   `json:"head"`
+	Next        *MergeBaseNext           `json:"next"`
+	Message     string                   `json:"message,omitempty"`
+	MergeCommit *github.RepositoryCommit `json:"mergeCommit,omitempty"`
+	Branch      string                   `json:"branch,omitempty"`
+	PullRequest *github.PullRequest      `json:"pullRequest,omitempty"`
+}
+
+// Merge brings the merge-base-next commit of base and head into the base branch.
+// With MergeMethodMerge the commit is merged directly; with MergeMethodPullRequest a branch is created at the commit
+// and a pull request into base is opened, titled with the first line of the message.
+// Nothing is written when there is no next commit or opts.DryRun is set.
+func (c *Client) Merge(base string, head string, opts MergeOptions) (*MergeResult, error) {
+	backend, ok := c.backend.(MergeBackend)
+	if !ok && !opts.DryRun {
+		return nil, ErrMergeUnsupported
+	}
+	if opts.Method == "" {
+		opts.Method = MergeMethodMerge
+	}
+	if opts.Method != MergeMethodMerge && opts.Method != MergeMethodPullRequest {
+		return nil, fmt.Errorf("unknown merge method %q", opts.Method)
+	}
+
+	next, err := c.GetMergeBaseNext(base, head, opts.Steps)
+	if err != nil {
+		return nil, err
+	}
+	result := &MergeResult{
+		Method: opts.Method,
+		DryRun: opts.DryRun,
+		Base:   base,
+		Head:   head,
+		Next:   next,
+	}
+	if next.Commit == nil {
+		return result, nil
+	}
+
+	data := newMergeMessageData(base, head, next)
+	result.Message, err = executeTemplate("message", opts.MessageTemplate, DefaultMergeMessageTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Method == MergeMethodPullRequest {
+		result.Branch, err = executeTemplate("branch", opts.BranchTemplate, DefaultBranchTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	switch opts.Method {
+	case MergeMethodMerge:
+		result.MergeCommit, err = backend.Merge(c.ctx, base, next.SHA, result.Message)
+		if err != nil {
+			return nil, fmt.Errorf("error merging %s into %s: %w", next.SHA, base, err)
+		}
+	case MergeMethodPullRequest:
+		if err := backend.CreateBranch(c.ctx, result.Branch, next.SHA); err != nil {
+			return nil, fmt.Errorf("error creating branch %s: %w", result.Branch, err)
+		}
+		title, body, _ := strings.Cut(result.Message, "\n")
+		result.PullRequest, err = backend.CreatePullRequest(c.ctx, &github.NewPullRequest{
+			Title: github.Ptr(title),
+			Head:  github.Ptr(result.Branch),
+			Base:  github.Ptr(base),
+			Body:  github.Ptr(strings.TrimSpace(body)),
+		})
+		if err != nil {
+			err = fmt.Errorf("error creating pull request from %s into %s: %w", result.Branch, base, err)
+			// the branch name is deterministic, so a leftover branch would make every retry fail
+			if deleteErr := backend.DeleteBranch(context.WithoutCancel(c.ctx), result.Branch); deleteErr != nil {
+				err = errors.Join(err, fmt.Errorf("error deleting branch %s: %w", result.Branch, deleteErr))
+			}
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func newMergeMessageData(base string, head string, next *MergeBaseNext) MergeMessageData {
+	message := next.Commit.GetCommit().GetMessage()
+	subject, _, _ := strings.Cut(message, "\n")
+	return MergeMessageData{
+		Base:     base,
+		Head:     head,
+		SHA:      next.SHA,
+		ShortSHA: next.SHA[:min(len(next.SHA), 7)],
+		Subject:  subject,
+		Message:  message,
+		Author:   next.Commit.GetCommit().GetAuthor().GetName(),
+		Depth:    next.Depth,
+	}
+}
+
+func executeTemplate(name string, text string, defaultText string, data MergeMessageData) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s template: %w", name, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error executing %s template: %w", name, err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
@@ -0,0 +1,285 @@
+package mergebasenext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// crissCrossGraph has two best common ancestors: main and feature each merged the other's first commit
+var crissCrossGraph = `
+main    -> m2
+feature -> f2
+
+f2 <- f1 m1 : F2: Merge main into feature
+m2 <- m1 f1 : M2: Merge feature into main
+f1 <- a1    : F1: Feature change
+m1 <- a1    : M1: Main change
+a1          : A: Initial commit
+`
+
+// TestWithMergeBases tests reporting the merge-base commit and every merge-base candidate
+func TestWithMergeBases(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Graph      string
+		Base       string
+		Head       string
+		MergeBases []string
+		Message    string
+	}{
+		{
+			Name:       "SingleMergeBase",
+			Graph:      simpleMergeGraph,
+			Base:       "testdata/simple-merge/main",
+			Head:       "testdata/simple-merge/feature",
+			MergeBases: []string{"cdddb51"},
+			Message:    "A: Initial commit (merge-base)",
+		},
+		{
+			Name:       "MultipleMergeBases",
+			Graph:      multipleMergeBaseGraph,
+			Base:       "testdata/multiple-merge-base/branch1",
+			Head:       "testdata/multiple-merge-base/branch2",
+			MergeBases: []string{"24150a474baddbc32827c30e2147fe6b076be7d2", "8c7a9d4b44737dee39a8893358c3cfdd81172ffd"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			backend, err := NewMemoryBackend(tc.Graph)
+			if err != nil {
+				t.Fatalf("NewMemoryBackend failed: %v", err)
+			}
+			client := NewClientWithBackend(context.Background(), backend, WithMergeBases())
+			result, err := client.GetMergeBaseNext(tc.Base, tc.Head, 1)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.MergeBaseCommit == nil || result.MergeBaseCommit.GetSHA() != result.MergeBase {
+				t.Fatalf("Expected merge-base commit %s, got %v", result.MergeBase, result.MergeBaseCommit)
+			}
+			if tc.Message != "" && result.MergeBaseCommit.GetCommit().GetMessage() != tc.Message {
+				t.Errorf("Expected merge-base message %q, got %q", tc.Message, result.MergeBaseCommit.GetCommit().GetMessage())
+			}
+			if len(result.MergeBases) == 0 || result.MergeBases[0] != result.MergeBase {
+				t.Errorf("Expected merge-bases to start with %s, got %v", result.MergeBase, result.MergeBases)
+			}
+			if !slices.Equal(slices.Sorted(slices.Values(result.MergeBases)), tc.MergeBases) {
+				t.Errorf("Expected merge-bases %v, got %v", tc.MergeBases, result.MergeBases)
+			}
+		})
+	}
+
+	client := newGraphClient(t, crissCrossGraph)
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.MergeBaseCommit != nil || result.MergeBases != nil {
+		t.Errorf("Expected no merge-bases by default, got %v %v", result.MergeBaseCommit, result.MergeBases)
+	}
+}
+
+// TestMergeBaseReport tests selecting the merge-base of a criss-cross history
+func TestMergeBaseReport(t *testing.T) {
+	backend, err := NewMemoryBackend(crissCrossGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	// the comparison reports f1, but m1 was committed later
+	backend.commits["f1"].Commit.Committer = &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	backend.commits["m1"].Commit.Committer = &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}}
+
+	testCases := []struct {
+		Report     MergeBaseReport
+		MergeBase  string
+		MergeBases []string
+		Error      error
+	}{
+		{Report: MergeBaseReportGitHub, MergeBase: "f1"},
+		{Report: MergeBaseReportNewest, MergeBase: "m1"},
+		{Report: MergeBaseReportNewest, MergeBase: "m1", MergeBases: []string{"m1", "f1"}},
+		{Report: MergeBaseReportFail, Error: ErrMultipleMergeBases},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.Report), func(t *testing.T) {
+			opts := []ClientOption{WithMergeBaseReport(tc.Report)}
+			if tc.MergeBases != nil {
+				opts = append(opts, WithMergeBases())
+			}
+			client := NewClientWithBackend(context.Background(), backend, opts...)
+			result, err := client.GetMergeBaseNext("main", "feature", 1)
+			if tc.Error != nil {
+				if !errors.Is(err, tc.Error) {
+					t.Fatalf("Expected error %v, got %v", tc.Error, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.SHA != "f2" {
+				t.Errorf("Expected next commit f2 with every report, got %s", result.SHA)
+			}
+			if result.MergeBase != tc.MergeBase || !slices.Equal(result.MergeBases, tc.MergeBases) {
+				t.Errorf("Expected merge-base %s of %v, got %s of %v", tc.MergeBase, tc.MergeBases, result.MergeBase, result.MergeBases)
+			}
+			if tc.MergeBases != nil && result.MergeBaseCommit.GetSHA() != tc.MergeBase {
+				t.Errorf("Expected merge-base commit %s, got %s", tc.MergeBase, result.MergeBaseCommit.GetSHA())
+			}
+			if tc.MergeBases == nil && result.MergeBaseCommit != nil {
+				t.Errorf("Expected no merge-base commit without WithMergeBases, got %s", result.MergeBaseCommit.GetSHA())
+			}
+		})
+	}
+
+	client := NewClientWithBackend(context.Background(), backend, WithMergeBaseReport(MergeBaseReportFail))
+	if _, err := client.GetMergeBaseNext("main", "m1", 1); err != nil {
+		t.Errorf("Expected a single merge-base to pass with the fail report, got %v", err)
+	}
+}
+
+// TestGitBackendListMergeBases tests listing the merge-bases of a criss-cross history with git merge-base --all
+func TestGitBackendListMergeBases(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	f.commit("F1", "F1: Feature change")
+	f.git("checkout", "--quiet", "main")
+	f.commit("M1", "M1: Main change")
+	f.git("merge", "--quiet", "--no-ff", "--no-edit", "F1")
+	f.git("checkout", "--quiet", "feature")
+	f.git("merge", "--quiet", "--no-ff", "--no-edit", "M1")
+
+	client, err := NewLocalClient(context.Background(), f.dir, WithMergeBases())
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	expected := slices.Sorted(slices.Values([]string{f.sha("F1"), f.sha("M1")}))
+	if !slices.Equal(slices.Sorted(slices.Values(result.MergeBases)), expected) {
+		t.Errorf("Expected merge-bases %v, got %v", expected, result.MergeBases)
+	}
+	message := result.MergeBaseCommit.GetCommit().GetMessage()
+	if message != "F1: Feature change" && message != "M1: Main change" {
+		t.Errorf("Expected the merge-base commit message, got %q", message)
+	}
+}
+
+// TestGitHubBackendListMergeBases tests finding every merge-base through the compare API, which reports only one,
+// reusing the comparison of base and head and comparing each other candidate once
+func TestGitHubBackendListMergeBases(t *testing.T) {
+	memory, err := NewMemoryBackend(crissCrossGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	requests := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.PathValue("basehead"))
+		base, head, _ := strings.Cut(r.PathValue("basehead"), "...")
+		comparison, err := memory.CompareCommits(r.Context(), base, head)
+		if err != nil {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		comparison.BaseCommit = memory.commits[base]
+		_ = json.NewEncoder(w).Encode(comparison)
+	})
+
+	comparison, err := memory.CompareCommits(t.Context(), "main", "feature")
+	if err != nil {
+		t.Fatalf("CompareCommits failed: %v", err)
+	}
+	backend := newTestServerClient(t, mux).backend.(*GitHubBackend)
+	mergeBases, err := backend.ListMergeBases(t.Context(), "main", "feature", comparison)
+	if err != nil {
+		t.Fatalf("ListMergeBases failed: %v", err)
+	}
+	if shas := commitSHAs(mergeBases); !slices.Equal(shas, []string{"f1", "m1"}) {
+		t.Errorf("Expected merge-bases [f1 m1], got %v", shas)
+	}
+	if mergeBases[1].GetCommit().GetMessage() != memory.commits["m1"].GetCommit().GetMessage() {
+		t.Errorf("Expected the commit of m1, got %+v", mergeBases[1])
+	}
+	if !slices.Equal(requests, []string{"m1...f1"}) {
+		t.Errorf("Expected a single comparison of m1 with the reported merge-base f1, got %v", requests)
+	}
+}
+
+// manyCandidatesGraph has a feature merge with more parents on main than GitHubBackend compares with each other
+var manyCandidatesGraph = `
+main    -> m9
+feature -> f1
+
+f1 <- m9 m8 m7 m6 m5 m4 m3 m2 m1 a1 : F1: Octopus merge of main
+m9 <- m8 : M9
+m8 <- m7 : M8
+m7 <- m6 : M7
+m6 <- m5 : M6
+m5 <- m4 : M5
+m4 <- m3 : M4
+m3 <- m2 : M3
+m2 <- m1 : M2
+m1 <- a1 : M1
+a1       : A: Initial commit
+`
+
+// TestGitHubBackendMergeBasesUndetermined tests that the newest and fail reports fail when the merge-base candidates
+// are too many to compare, instead of reporting the merge-base the compare API picks
+func TestGitHubBackendMergeBasesUndetermined(t *testing.T) {
+	memory, err := NewMemoryBackend(manyCandidatesGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+		base, head, _ := strings.Cut(r.PathValue("basehead"), "...")
+		comparison, err := memory.CompareCommits(r.Context(), base, head)
+		if err != nil {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(comparison)
+	})
+	mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/commits/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		sha, err := memory.GetCommitSHA1(r.Context(), r.PathValue("ref"))
+		if err != nil {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(sha))
+	})
+
+	testCases := []struct {
+		Report MergeBaseReport
+		Error  error
+	}{
+		{Report: MergeBaseReportGitHub},
+		{Report: MergeBaseReportNewest, Error: ErrMergeBasesUndetermined},
+		{Report: MergeBaseReportFail, Error: ErrMergeBasesUndetermined},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.Report), func(t *testing.T) {
+			client := newTestServerClient(t, mux)
+			WithMergeBaseReport(tc.Report)(client)
+			result, err := client.GetMergeBaseNext("main", "feature", 1)
+			if !errors.Is(err, tc.Error) {
+				t.Fatalf("Expected error %v, got %v", tc.Error, err)
+			}
+			if tc.Error == nil && (result.SHA != "f1" || result.MergeBase != "m9") {
+				t.Errorf("Expected f1 past the merge-base m9, got %s past %s", result.SHA, result.MergeBase)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+func newMergeTestClient(t *testing.T) (*Client, *MemoryBackend) {
+	backend, err := NewMemoryBackend(simpleMergeGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	return NewClientWithBackend(context.Background(), backend), backend
+}
+
+// TestMergeCommit tests merging the next commit into the base branch one step at a time
+func TestMergeCommit(t *testing.T) {
+	client, backend := newMergeTestClient(t)
+	base := "testdata/simple-merge/main"
+	head := "testdata/simple-merge/feature"
+
+	result, err := client.Merge(base, head, MergeOptions{Method: MergeMethodMerge})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if result.Next.SHA != "d761e77fe7bbc5fd65e8ee14b8a65ea2ff1f0043" {
+		t.Errorf("Expected next SHA d761e77..., got %s", result.Next.SHA)
+	}
+	expectedMessage := "Merge d761e77 into testdata/simple-merge/main\n\nC: Feature development 1"
+	if result.Message != expectedMessage {
+		t.Errorf("Expected message %q, got %q", expectedMessage, result.Message)
+	}
+	if result.MergeCommit == nil {
+		t.Fatalf("Expected a merge commit")
+	}
+	mainSHA, _ := backend.GetCommitSHA1(context.Background(), base)
+	if mainSHA != result.MergeCommit.GetSHA() {
+		t.Errorf("Expected %s to point at merge commit %s, got %s", base, result.MergeCommit.GetSHA(), mainSHA)
+	}
+
+	result, err = client.Merge(base, head, MergeOptions{Method: MergeMethodMerge, MessageTemplate: "{{.Subject}} ({{.Depth}} left)"})
+	if err != nil {
+		t.Fatalf("Second Merge failed: %v", err)
+	}
+	if result.Next.SHA != "904d00b" || result.Message != "D: Feature development 2 (1 left)" {
+		t.Errorf("Unexpected second merge: next %s, message %q", result.Next.SHA, result.Message)
+	}
+
+	result, err = client.Merge(base, head, MergeOptions{Method: MergeMethodMerge})
+	if err != nil {
+		t.Fatalf("Third Merge failed: %v", err)
+	}
+	if result.Next.Status != StatusUpToDate || result.MergeCommit != nil {
+		t.Errorf("Expected nothing to merge once head is merged, got %+v", result)
+	}
+}
+
+// TestMergePullRequest tests opening a pull request from a temporary branch at the next commit
+func TestMergePullRequest(t *testing.T) {
+	client, backend := newMergeTestClient(t)
+	result, err := client.Merge("testdata/simple-merge/main", "testdata/simple-merge/feature", MergeOptions{Method: MergeMethodPullRequest})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if result.Branch != "merge-base-next/testdata/simple-merge/main/d761e77" {
+		t.Errorf("Unexpected branch %s", result.Branch)
+	}
+	branchSHA, err := backend.GetCommitSHA1(context.Background(), result.Branch)
+	if err != nil || branchSHA != result.Next.SHA {
+		t.Errorf("Expected branch at %s, got %s (%v)", result.Next.SHA, branchSHA, err)
+	}
+	pr := result.PullRequest
+	if pr == nil {
+		t.Fatalf("Expected a pull request")
+	}
+	if pr.GetTitle() != "Merge d761e77 into testdata/simple-merge/main" || pr.GetBody() != "C: Feature development 1" {
+		t.Errorf("Unexpected pull request title %q and body %q", pr.GetTitle(), pr.GetBody())
+	}
+	if pr.GetBase().GetRef() != "testdata/simple-merge/main" || pr.GetHead().GetRef() != result.Branch {
+		t.Errorf("Unexpected pull request %s <- %s", pr.GetBase().GetRef(), pr.GetHead().GetRef())
+	}
+}
+
+// failingPullRequestBackend fails to open the first failures pull requests
+type failingPullRequestBackend struct {
+	*MemoryBackend
+	failures int
+}
+
+func (b *failingPullRequestBackend) CreatePullRequest(ctx context.Context, pull *github.NewPullRequest) (*github.PullRequest, error) {
+	if b.failures > 0 {
+		b.failures--
+		return nil, errors.New("502 Bad Gateway")
+	}
+	return b.MemoryBackend.CreatePullRequest(ctx, pull)
+}
+
+// TestMergePullRequestRetry tests that the branch is deleted when the pull request cannot be opened, so that a retry can create it again
+func TestMergePullRequestRetry(t *testing.T) {
+	_, memory := newMergeTestClient(t)
+	client := NewClientWithBackend(context.Background(), &failingPullRequestBackend{MemoryBackend: memory, failures: 1})
+	base := "testdata/simple-merge/main"
+	head := "testdata/simple-merge/feature"
+	branch := "merge-base-next/testdata/simple-merge/main/d761e77"
+
+	if _, err := client.Merge(base, head, MergeOptions{Method: MergeMethodPullRequest}); err == nil {
+		t.Fatalf("Expected the pull request to fail")
+	}
+	if _, err := memory.GetCommitSHA1(context.Background(), branch); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected branch %s to be deleted, got %v", branch, err)
+	}
+	result, err := client.Merge(base, head, MergeOptions{Method: MergeMethodPullRequest})
+	if err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	if result.Branch != branch || result.PullRequest.GetHead().GetRef() != branch {
+		t.Errorf("Expected a pull request from %s, got %+v", branch, result.PullRequest)
+	}
+}
+
+// TestMergeDryRun tests that a dry run reports the merge without writing to the repository
+func TestMergeDryRun(t *testing.T) {
+	client, backend := newMergeTestClient(t)
+	result, err := client.Merge("testdata/simple-merge/main", "testdata/simple-merge/feature", MergeOptions{Method: MergeMethodPullRequest, DryRun: true})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if result.Branch == "" || result.Message == "" {
+		t.Errorf("Expected dry run to render branch and message, got %+v", result)
+	}
+	if result.PullRequest != nil || len(backend.pulls) != 0 {
+		t.Errorf("Expected no pull request in a dry run")
+	}
+	if _, err := backend.GetCommitSHA1(context.Background(), result.Branch); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected branch %s not to be created, got %v", result.Branch, err)
+	}
+}
+
+// TestMergeErrors tests invalid merge options
+func TestMergeErrors(t *testing.T) {
+	client, _ := newMergeTestClient(t)
+	testCases := []struct {
+		Name string
+		Opts MergeOptions
+	}{
+		{Name: "UnknownMethod", Opts: MergeOptions{Method: "rebase"}},
+		{Name: "InvalidTemplate", Opts: MergeOptions{MessageTemplate: "{{.Subject"}},
+		{Name: "UnknownField", Opts: MergeOptions{MessageTemplate: "{{.Title}}"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if _, err := client.Merge("testdata/simple-merge/main", "testdata/simple-merge/feature", tc.Opts); err == nil {
+				t.Errorf("Expected error but got none")
+			}
+		})
+	}
+}
@@ -48,6 +48,25 @@ import (
 	"testing"
 )
 
+// multipleMergeBaseGraph describes the commit graph above for the offline test backend
+var multipleMergeBaseGraph = `
+testdata/multiple-merge-base/main    -> 120ed78
+testdata/multiple-merge-base/branchA -> 24150a4
+testdata/multiple-merge-base/branchB -> 8c7a9d4
+testdata/multiple-merge-base/branch1 -> a7d32e1
+testdata/multiple-merge-base/branch2 -> d9b05a4
+
+d9b05a4                                  <- beb86f7         : I: Branch2 specific development
+beb86f714f463ead9c78e993715a168d3514a20a <- 8c7a9d4 24150a4 : H: Merge branchA into branch2
+a7d32e1                                  <- 0e66bd3         : G: Branch1 specific development
+0e66bd36c2ce00729842c094da0cfb14b217533d <- 24150a4 8c7a9d4 : F: Merge branchB into branch1
+8c7a9d4b44737dee39a8893358c3cfdd81172ffd <- b265c24         : E: Common work on path B
+b265c2435cd016f47413df25f4655962cc5bbaf5 <- 120ed78         : C: Branch B initial work
+24150a474baddbc32827c30e2147fe6b076be7d2 <- a8184be         : D: Common work on path A
+a8184be5d737c2751486ea88bcc9151e9ce4b8e1 <- 120ed78         : B: Branch A initial work
+120ed78                                                     : A: Initial commit (root)
+`
+
 // TestMultipleMergeBase tests scenarios where git merge-base --all returns multiple commits
 func TestMultipleMergeBase(t *testing.T) {
 	testCases := []TestCase{
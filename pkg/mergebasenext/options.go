@@ -0,0 +1,102 @@
+package mergebasenext
+
+import "net/http"
+
+// ClientOption configures how a Client walks the commit graph.
+type ClientOption func(*Client)
+
+// WithMaxDepth limits the number of commits the first-parent walk visits from head.
+// Zero or a negative value means no limit.
+func WithMaxDepth(maxDepth int) ClientOption {
+	return func(c *Client) {
+		c.maxDepth = maxDepth
+	}
+}
+
+// WithParentIndex makes the walk follow the parent at index (0-based) of every merge commit instead of the first parent.
+// Commits with a single parent always follow it, and a merge commit without a parent at index is an error.
+func WithParentIndex(index int) ClientOption {
+	return func(c *Client) {
+		c.parentIndex = index
+	}
+}
+
+// WithSkipFilter makes GetMergeBaseNext advance past commits matching filter, reporting them as skipped.
+func WithSkipFilter(filter SkipFilter) ClientOption {
+	return func(c *Client) {
+		if !filter.IsZero() {
+			c.skipFilter = &filter
+		}
+	}
+}
+
+// WithPullRequests makes GetMergeBaseNext look up the pull request the next commit belongs to.
+func WithPullRequests() ClientOption {
+	return func(c *Client) {
+		c.withPullRequests = true
+	}
+}
+
+// WithMergeBases makes the results include the merge-base commit and every merge-base candidate of criss-cross histories.
+func WithMergeBases() ClientOption {
+	return func(c *Client) {
+		c.withMergeBases = true
+	}
+}
+
+// WithMergeBaseReport selects which merge-base of criss-cross histories the results report, without changing the next commit.
+// Any report other than MergeBaseReportGitHub lists the merge-base candidates; the results include them only with WithMergeBases.
+func WithMergeBaseReport(report MergeBaseReport) ClientOption {
+	return func(c *Client) {
+		c.mergeBaseReport = report
+	}
+}
+
+// WithFiles makes GetMergeBaseNext summarize the files the next commit changes and their CODEOWNERS owners.
+func WithFiles() ClientOption {
+	return func(c *Client) {
+		c.withFiles = true
+	}
+}
+
+// WithToken makes NewClient authenticate with token instead of the gh token for the host.
+// Clients of other backends ignore it.
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.auth.Token = token
+	}
+}
+
+// WithGitHubApp makes NewClient authenticate as the installationID installation of the GitHub App appID,
+// minting installation tokens with the PEM encoded privateKey of the app. Clients of other backends ignore it.
+func WithGitHubApp(appID int64, installationID int64, privateKey []byte) ClientOption {
+	return func(c *Client) {
+		c.auth.AppID = appID
+		c.auth.InstallationID = installationID
+		c.auth.PrivateKey = privateKey
+	}
+}
+
+// WithHostname makes NewClient read the repository on the GitHub host hostname, such as a GitHub Enterprise Server,
+// when the repository is given as OWNER/REPO or taken from the current directory. Clients of other backends ignore it.
+func WithHostname(hostname string) ClientOption {
+	return func(c *Client) {
+		c.hostname = normalizeHostname(hostname)
+	}
+}
+
+// WithTransport makes NewClient send its API requests with transport, such as one that trusts the private CA of a GitHub Enterprise Server.
+// Clients of other backends ignore it.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithRetryPolicy makes NewClient retry rate limited requests with policy instead of DefaultRetryPolicy.
+// Clients of other backends ignore it.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
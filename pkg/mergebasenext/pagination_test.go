@@ -0,0 +1,142 @@
+package mergebasenext
+
+/*
+## Long History Scenario
+
+```text
+* c300 (feature) Feature work 300
+* ...
+* c002           Feature work 2
+* c001           Feature work 1
+* c000 (main)    Initial commit (merge-base)
+```
+
+The compare API returns at most 250 commits unless the response is paginated,
+so the first-parent walk from c300 can only reach c001 by paging through the
+whole comparison.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+const compareCommitLimit = 250
+
+func longHistorySHA(i int) string {
+	return fmt.Sprintf("%040x", i)
+}
+
+// newLongHistoryHandler serves a linear history of n commits on top of the merge-base.
+// When paginate is false the comparison is always truncated like an unpaginated response.
+func newLongHistoryHandler(t *testing.T, n int, paginate bool) http.Handler {
+	commits := make([]*github.RepositoryCommit, 0, n)
+	for i := 1; i <= n; i++ {
+		commits = append(commits, &github.RepositoryCommit{
+			SHA:     github.Ptr(longHistorySHA(i)),
+			Parents: []*github.Commit{{SHA: github.Ptr(longHistorySHA(i - 1))}},
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/compare/main...feature", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if page < 1 {
+			page = 1
+		}
+		if perPage < 1 || !paginate {
+			// without pagination the commit list is truncated
+			page, perPage = 1, compareCommitLimit
+		}
+		start := min((page-1)*perPage, len(commits))
+		end := min(start+perPage, len(commits))
+		if end < len(commits) && paginate {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d&per_page=%d>; rel="next"`, r.URL.Path, page+1, perPage))
+		}
+		err := json.NewEncoder(w).Encode(&github.CommitsComparison{
+			Status:          github.Ptr("ahead"),
+			AheadBy:         github.Ptr(len(commits)),
+			TotalCommits:    github.Ptr(len(commits)),
+			MergeBaseCommit: &github.RepositoryCommit{SHA: github.Ptr(longHistorySHA(0))},
+			Commits:         commits[start:end],
+		})
+		if err != nil {
+			t.Errorf("Failed to encode comparison: %v", err)
+		}
+	})
+	mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/commits/feature", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, longHistorySHA(n))
+	})
+	return mux
+}
+
+// TestPaginatedComparison tests that the first-parent walk reaches commits beyond the compare API limit
+func TestPaginatedComparison(t *testing.T) {
+	n := compareCommitLimit + 50
+	client := newTestServerClient(t, newLongHistoryHandler(t, n, true))
+
+	comparison, err := client.backend.CompareCommits(client.ctx, "main", "feature")
+	if err != nil {
+		t.Fatalf("CompareCommits failed: %v", err)
+	}
+	if len(comparison.Commits) != n {
+		t.Errorf("Expected %d commits, got %d", n, len(comparison.Commits))
+	}
+
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != longHistorySHA(1) {
+		t.Errorf("Expected SHA %s, got %s", longHistorySHA(1), result.SHA)
+	}
+	if result.Depth != n {
+		t.Errorf("Expected Depth %d, got %d", n, result.Depth)
+	}
+	if result.Status != StatusAhead {
+		t.Errorf("Expected Status %s, got %s", StatusAhead, result.Status)
+	}
+
+	last, err := client.GetMergeBaseNext("main", "feature", n+10)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if last.SHA != longHistorySHA(n) || last.Depth != 1 {
+		t.Errorf("Expected head %s at Depth 1, got %s at Depth %d", longHistorySHA(n), last.SHA, last.Depth)
+	}
+
+	path, err := client.GetMergeBasePath("main", "feature")
+	if err != nil {
+		t.Fatalf("GetMergeBasePath failed: %v", err)
+	}
+	if len(path.Commits) != n {
+		t.Fatalf("Expected %d commits in path, got %d", n, len(path.Commits))
+	}
+	for i, commit := range path.Commits {
+		if commit.GetSHA() != longHistorySHA(i+1) {
+			t.Errorf("Expected path[%d] SHA %s, got %s", i, longHistorySHA(i+1), commit.GetSHA())
+		}
+	}
+}
+
+// TestTruncatedComparison tests that a head missing from a truncated comparison is reported as truncated
+func TestTruncatedComparison(t *testing.T) {
+	client := newTestServerClient(t, newLongHistoryHandler(t, compareCommitLimit+50, false))
+
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != "" {
+		t.Errorf("Expected empty SHA, got %s", result.SHA)
+	}
+	if result.Status != StatusTruncated {
+		t.Errorf("Expected Status %s, got %s", StatusTruncated, result.Status)
+	}
+}
@@ -0,0 +1,117 @@
+package mergebasenext
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Input formats accepted by ParsePairs
+const (
+	PairFormatAuto = "auto"
+	PairFormatCSV  = "csv"
+	PairFormatJSON = "json"
+	PairFormatYAML = "yaml"
+)
+
+var PairFormats = []string{PairFormatAuto, PairFormatCSV, PairFormatJSON, PairFormatYAML}
+
+// ParsePairs reads base/head pairs from r.
+//
+//   - csv: one "base,head" record per line; blank lines, lines starting with '#' and a "base,head" header are skipped
+//   - json: an array of {"base": ..., "head": ...} objects, or one such object per line
+//   - yaml: a sequence of mappings with base and head keys
+//   - auto: json when the input starts with '[' or '{', yaml when it starts with '-', csv otherwise
+func ParsePairs(r io.Reader, format string) ([]Pair, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" || format == PairFormatAuto {
+		format = detectPairFormat(data)
+	}
+
+	var pairs []Pair
+	switch format {
+	case PairFormatCSV:
+		pairs, err = parseCSVPairs(data)
+	case PairFormatJSON:
+		pairs, err = parseJSONPairs(data)
+	case PairFormatYAML:
+		err = yaml.Unmarshal(data, &pairs)
+	default:
+		return nil, fmt.Errorf("unknown pair format %q: must be one of %s", format, strings.Join(PairFormats, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s pairs: %w", format, err)
+	}
+	for i, pair := range pairs {
+		if pair.Base == "" || pair.Head == "" {
+			return nil, fmt.Errorf("pair %d: both base and head are required", i+1)
+		}
+	}
+	return pairs, nil
+}
+
+func detectPairFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")), bytes.HasPrefix(trimmed, []byte("{")):
+		return PairFormatJSON
+	case bytes.HasPrefix(trimmed, []byte("-")):
+		return PairFormatYAML
+	default:
+		return PairFormatCSV
+	}
+}
+
+func parseCSVPairs(data []byte) ([]Pair, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	pairs := []Pair{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return pairs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		base, head := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if len(pairs) == 0 && base == "base" && head == "head" {
+			continue
+		}
+		pairs = append(pairs, Pair{Base: base, Head: head})
+	}
+}
+
+func parseJSONPairs(data []byte) ([]Pair, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		pairs := []Pair{}
+		err := json.Unmarshal(data, &pairs)
+		return pairs, err
+	}
+	pairs := []Pair{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var pair Pair
+		if err := json.Unmarshal(line, &pair); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, scanner.Err()
+}
@@ -0,0 +1,131 @@
+package mergebasenext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// PullRequestOptions configures Client.OpenPullRequest.
+type PullRequestOptions struct {
+	Steps           int
+	DryRun          bool
+	MessageTemplate string
+	BranchTemplate  string
+	// FallbackTeam is the team slug, optionally prefixed with the organization, requested for review
+	// when the next commit's author has no GitHub login.
+	FallbackTeam string
+	// Assign also assigns the requested user reviewer to the pull request.
+	Assign bool
+}
+
+// PullRequestResult describes the pull request opened by Client.OpenPullRequest and who was asked to review it.
+type PullRequestResult struct {
+	MergeResult
+	Reviewers     []string `json:"reviewers"`
+	TeamReviewers []string `json:"teamReviewers"`
+	Assignees     []string `json:"assignees"`
+	// Warnings lists the review requests and assignments that failed after the pull request was opened.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// OpenPullRequest opens a pull request into base from a branch at the merge-base-next commit of base and head,
+// and requests review from the author of that commit so they can resolve conflicts with their own changes.
+// When the author has no GitHub login, or cannot be requested for review, review is requested from opts.FallbackTeam instead.
+// Once the pull request is open, failing to request review or to assign it is recorded in the Warnings of the result rather than returned,
+// so that the caller still learns the pull request.
+func (c *Client) OpenPullRequest(base string, head string, opts PullRequestOptions) (*PullRequestResult, error) {
+	merge, err := c.Merge(base, head, MergeOptions{
+		Method:          MergeMethodPullRequest,
+		Steps:           opts.Steps,
+		DryRun:          opts.DryRun,
+		MessageTemplate: opts.MessageTemplate,
+		BranchTemplate:  opts.BranchTemplate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &PullRequestResult{
+		MergeResult:   *merge,
+		Reviewers:     []string{},
+		TeamReviewers: []string{},
+		Assignees:     []string{},
+	}
+	if merge.Next.Commit == nil {
+		return result, nil
+	}
+
+	if login := authorLogin(merge.Next.Commit); login != "" {
+		result.Reviewers = append(result.Reviewers, login)
+		if opts.Assign {
+			result.Assignees = append(result.Assignees, login)
+		}
+	} else if opts.FallbackTeam != "" {
+		result.TeamReviewers = append(result.TeamReviewers, teamSlug(opts.FallbackTeam))
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	backend, ok := c.backend.(MergeBackend)
+	if !ok {
+		return nil, ErrMergeUnsupported
+	}
+	number := merge.PullRequest.GetNumber()
+	if len(result.Reviewers) > 0 || len(result.TeamReviewers) > 0 {
+		c.requestReview(backend, number, result, opts.FallbackTeam)
+	}
+	if len(result.Assignees) > 0 {
+		if err := backend.AddAssignees(c.ctx, number, result.Assignees); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("error assigning pull request #%d to %s: %v", number, strings.Join(result.Assignees, ", "), err))
+			result.Assignees = []string{}
+		}
+	}
+	return result, nil
+}
+
+// requestReview requests review on pull request number from the reviewers of result, falling back to fallbackTeam
+// when the author cannot be requested, such as an outside collaborator without access to the repository.
+// The reviewers of result are updated to those actually requested.
+func (c *Client) requestReview(backend MergeBackend, number int, result *PullRequestResult, fallbackTeam string) {
+	pr, err := backend.RequestReviewers(c.ctx, number, github.ReviewersRequest{
+		Reviewers:     result.Reviewers,
+		TeamReviewers: result.TeamReviewers,
+	})
+	if err != nil && len(result.Reviewers) > 0 && fallbackTeam != "" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("error requesting review from %s on pull request #%d, requesting %s instead: %v", strings.Join(result.Reviewers, ", "), number, fallbackTeam, err))
+		result.Reviewers = []string{}
+		result.TeamReviewers = []string{teamSlug(fallbackTeam)}
+		pr, err = backend.RequestReviewers(c.ctx, number, github.ReviewersRequest{
+			Reviewers:     result.Reviewers,
+			TeamReviewers: result.TeamReviewers,
+		})
+	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("error requesting review on pull request #%d: %v", number, err))
+		result.Reviewers = []string{}
+		result.TeamReviewers = []string{}
+		return
+	}
+	result.PullRequest = pr
+}
+
+// authorLogin returns the GitHub login of the commit author, or "" when the author is not a GitHub user.
+// Bot accounts cannot review pull requests, so they count as having no login.
+func authorLogin(commit *github.RepositoryCommit) string {
+	login := commit.GetAuthor().GetLogin()
+	if strings.HasSuffix(login, "[bot]") {
+		return ""
+	}
+	return login
+}
+
+// teamSlug returns the slug of a team given as "org/slug" or "slug".
+func teamSlug(team string) string {
+	_, slug, found := strings.Cut(team, "/")
+	if !found {
+		return team
+	}
+	return slug
+}
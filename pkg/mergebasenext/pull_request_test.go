@@ -0,0 +1,174 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// pullRequestGraph has one feature commit by a GitHub user and one without a GitHub login
+var pullRequestGraph = `
+main    -> b1
+feature -> c2
+
+c2 <- c1            : C2: Commit without a GitHub login
+c1 <- a1 @octocat   : C1: Commit by a GitHub user
+b1 <- a1 @hubot     : B: Main branch development
+a1                  : A: Initial commit (merge-base)
+`
+
+// TestOpenPullRequest tests that review is requested from the next commit's author, or the fallback team without a login
+func TestOpenPullRequest(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Steps         int
+		Assign        bool
+		Reviewers     []string
+		TeamReviewers []string
+		Assignees     []string
+	}{
+		{Name: "Author", Steps: 1, Assign: true, Reviewers: []string{"octocat"}, TeamReviewers: []string{}, Assignees: []string{"octocat"}},
+		{Name: "AuthorWithoutAssign", Steps: 1, Reviewers: []string{"octocat"}, TeamReviewers: []string{}, Assignees: []string{}},
+		{Name: "FallbackTeam", Steps: 2, Assign: true, Reviewers: []string{}, TeamReviewers: []string{"mergers"}, Assignees: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			backend, err := NewMemoryBackend(pullRequestGraph)
+			if err != nil {
+				t.Fatalf("NewMemoryBackend failed: %v", err)
+			}
+			client := NewClientWithBackend(context.Background(), backend)
+			result, err := client.OpenPullRequest("main", "feature", PullRequestOptions{
+				Steps:        tc.Steps,
+				FallbackTeam: "example-org/mergers",
+				Assign:       tc.Assign,
+			})
+			if err != nil {
+				t.Fatalf("OpenPullRequest failed: %v", err)
+			}
+			if !slices.Equal(result.Reviewers, tc.Reviewers) || !slices.Equal(result.TeamReviewers, tc.TeamReviewers) || !slices.Equal(result.Assignees, tc.Assignees) {
+				t.Errorf("Expected reviewers %v, teams %v, assignees %v, got %v, %v, %v",
+					tc.Reviewers, tc.TeamReviewers, tc.Assignees, result.Reviewers, result.TeamReviewers, result.Assignees)
+			}
+
+			pr := result.PullRequest
+			if pr == nil {
+				t.Fatalf("Expected a pull request")
+			}
+			if pr.GetHead().GetSHA() != result.Next.SHA {
+				t.Errorf("Expected pull request head at %s, got %s", result.Next.SHA, pr.GetHead().GetSHA())
+			}
+			reviewers := []string{}
+			for _, user := range pr.RequestedReviewers {
+				reviewers = append(reviewers, user.GetLogin())
+			}
+			teams := []string{}
+			for _, team := range pr.RequestedTeams {
+				teams = append(teams, team.GetSlug())
+			}
+			assignees := []string{}
+			for _, user := range pr.Assignees {
+				assignees = append(assignees, user.GetLogin())
+			}
+			if !slices.Equal(reviewers, tc.Reviewers) || !slices.Equal(teams, tc.TeamReviewers) || !slices.Equal(assignees, tc.Assignees) {
+				t.Errorf("Pull request has reviewers %v, teams %v, assignees %v", reviewers, teams, assignees)
+			}
+		})
+	}
+}
+
+// restrictedBackend fails to request review from and assign the logins of outsiders, like GitHub does for users without access
+type restrictedBackend struct {
+	*MemoryBackend
+	outsiders []string
+	teams     bool
+}
+
+func (b *restrictedBackend) RequestReviewers(ctx context.Context, number int, reviewers github.ReviewersRequest) (*github.PullRequest, error) {
+	if slices.ContainsFunc(reviewers.Reviewers, func(login string) bool { return slices.Contains(b.outsiders, login) }) ||
+		(!b.teams && len(reviewers.TeamReviewers) > 0) {
+		return nil, errors.New("422 Reviews may only be requested from collaborators")
+	}
+	return b.MemoryBackend.RequestReviewers(ctx, number, reviewers)
+}
+
+func (b *restrictedBackend) AddAssignees(ctx context.Context, number int, assignees []string) error {
+	if slices.ContainsFunc(assignees, func(login string) bool { return slices.Contains(b.outsiders, login) }) {
+		return errors.New("422 Validation Failed")
+	}
+	return b.MemoryBackend.AddAssignees(ctx, number, assignees)
+}
+
+// TestOpenPullRequestReviewFailure tests that the opened pull request is returned when review cannot be requested from the author,
+// falling back to the team and recording what failed
+func TestOpenPullRequestReviewFailure(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		FallbackTeam  string
+		Teams         bool
+		TeamReviewers []string
+		Warnings      int
+	}{
+		{Name: "FallbackTeam", FallbackTeam: "example-org/mergers", Teams: true, TeamReviewers: []string{"mergers"}, Warnings: 2},
+		{Name: "FallbackTeamFails", FallbackTeam: "example-org/mergers", TeamReviewers: []string{}, Warnings: 3},
+		{Name: "NoFallbackTeam", TeamReviewers: []string{}, Warnings: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			memory, err := NewMemoryBackend(pullRequestGraph)
+			if err != nil {
+				t.Fatalf("NewMemoryBackend failed: %v", err)
+			}
+			backend := &restrictedBackend{MemoryBackend: memory, outsiders: []string{"octocat"}, teams: tc.Teams}
+			client := NewClientWithBackend(context.Background(), backend)
+			result, err := client.OpenPullRequest("main", "feature", PullRequestOptions{Steps: 1, FallbackTeam: tc.FallbackTeam, Assign: true})
+			if err != nil {
+				t.Fatalf("OpenPullRequest failed: %v", err)
+			}
+			if result.PullRequest.GetNumber() == 0 || len(memory.pulls) != 1 {
+				t.Fatalf("Expected the opened pull request, got %+v", result.PullRequest)
+			}
+			if len(result.Reviewers) != 0 || !slices.Equal(result.TeamReviewers, tc.TeamReviewers) || len(result.Assignees) != 0 {
+				t.Errorf("Expected teams %v only, got reviewers %v, teams %v, assignees %v", tc.TeamReviewers, result.Reviewers, result.TeamReviewers, result.Assignees)
+			}
+			if len(result.Warnings) != tc.Warnings {
+				t.Errorf("Expected %d warnings, got %q", tc.Warnings, result.Warnings)
+			}
+		})
+	}
+}
+
+// TestOpenPullRequestDryRun tests that a dry run reports the reviewers without opening a pull request
+func TestOpenPullRequestDryRun(t *testing.T) {
+	backend, err := NewMemoryBackend(pullRequestGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	client := NewClientWithBackend(context.Background(), backend)
+	result, err := client.OpenPullRequest("main", "feature", PullRequestOptions{DryRun: true, Assign: true})
+	if err != nil {
+		t.Fatalf("OpenPullRequest failed: %v", err)
+	}
+	if !slices.Equal(result.Reviewers, []string{"octocat"}) || result.PullRequest != nil || len(backend.pulls) != 0 {
+		t.Errorf("Unexpected dry run result %+v", result)
+	}
+}
+
+// TestAuthorLogin tests that bot accounts are not requested for review
+func TestAuthorLogin(t *testing.T) {
+	backend, err := NewMemoryBackend("a1 @dependabot[bot] : Bump\nb1 <- a1 @octocat : Fix")
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	bot, _ := backend.Commit("a1")
+	user, _ := backend.Commit("b1")
+	if login := authorLogin(bot); login != "" {
+		t.Errorf("Expected no login for a bot author, got %s", login)
+	}
+	if login := authorLogin(user); login != "octocat" {
+		t.Errorf("Expected login octocat, got %s", login)
+	}
+}
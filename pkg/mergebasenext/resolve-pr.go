@@ -0,0 +1,41 @@
+package mergebasenext
+
+import (
+	"fmt"
+)
+
+// SourcePullRequest describes the pull request base and head were resolved from by ResolvePullRequest.
+type SourcePullRequest struct {
+	PullRequestInfo
+	State string `json:"state"`
+	// BaseRef is the base branch, and HeadRef the head branch in HeadRepository, which is a fork for pull requests from forks.
+	BaseRef        string `json:"baseRef"`
+	HeadRef        string `json:"headRef"`
+	HeadRepository string `json:"headRepository"`
+	HeadSHA        string `json:"headSha"`
+}
+
+// ResolvePullRequest returns the base and head to compare for pull request number: its base branch and its head commit.
+// The head is the commit SHA rather than the branch, because the base repository also serves the head commits of pull requests from forks.
+func (c *Client) ResolvePullRequest(number int) (string, string, *SourcePullRequest, error) {
+	getter, ok := c.backend.(PullRequestGetter)
+	if !ok {
+		return "", "", nil, ErrPullRequestsUnsupported
+	}
+	pr, err := getter.GetPullRequest(c.ctx, number)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("error getting pull request #%d: %w", number, err)
+	}
+	source := &SourcePullRequest{
+		PullRequestInfo: *newPullRequestInfo(pr),
+		State:           pr.GetState(),
+		BaseRef:         pr.GetBase().GetRef(),
+		HeadRef:         pr.GetHead().GetRef(),
+		HeadRepository:  pr.GetHead().GetRepo().GetFullName(),
+		HeadSHA:         pr.GetHead().GetSHA(),
+	}
+	if source.BaseRef == "" || source.HeadSHA == "" {
+		return "", "", nil, fmt.Errorf("pull request #%d has no base branch or head commit", number)
+	}
+	return source.BaseRef, source.HeadSHA, source, nil
+}
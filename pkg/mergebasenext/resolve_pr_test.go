@@ -0,0 +1,94 @@
+package mergebasenext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// TestResolvePullRequest tests comparing the base branch and head commit of a pull request
+func TestResolvePullRequest(t *testing.T) {
+	backend, err := NewMemoryBackend(simpleMergeGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	pr, err := backend.CreatePullRequest(context.Background(), &github.NewPullRequest{
+		Title: github.Ptr("Feature"),
+		Base:  github.Ptr("testdata/simple-merge/main"),
+		Head:  github.Ptr("testdata/simple-merge/feature"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed: %v", err)
+	}
+	client := NewClientWithBackend(context.Background(), backend)
+	base, head, source, err := client.ResolvePullRequest(pr.GetNumber())
+	if err != nil {
+		t.Fatalf("ResolvePullRequest failed: %v", err)
+	}
+	if base != "testdata/simple-merge/main" || head != pr.GetHead().GetSHA() {
+		t.Errorf("Expected base testdata/simple-merge/main and head %s, got %s and %s", pr.GetHead().GetSHA(), base, head)
+	}
+	if source.Number != pr.GetNumber() || source.Title != "Feature" || source.State != "open" || source.HeadRef != "testdata/simple-merge/feature" {
+		t.Errorf("Unexpected source pull request %+v", source)
+	}
+	result, err := client.GetMergeBaseNext(base, head, 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != "d761e77fe7bbc5fd65e8ee14b8a65ea2ff1f0043" {
+		t.Errorf("Expected next commit d761e77, got %s", result.SHA)
+	}
+
+	if _, _, _, err := client.ResolvePullRequest(pr.GetNumber() + 1); err == nil {
+		t.Errorf("Expected an error for a missing pull request")
+	}
+}
+
+// TestGitHubBackendResolveForkPullRequest tests that the head of a pull request from a fork is compared by SHA in the base repository
+func TestGitHubBackendResolveForkPullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/srz-zumix/gh-merge-base-next/pulls/1234", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&github.PullRequest{
+			Number:  github.Ptr(1234),
+			State:   github.Ptr("open"),
+			Title:   github.Ptr("Fix from a fork"),
+			User:    &github.User{Login: github.Ptr("contributor")},
+			HTMLURL: github.Ptr("https://github.com/srz-zumix/gh-merge-base-next/pull/1234"),
+			Base:    &github.PullRequestBranch{Ref: github.Ptr("main"), SHA: github.Ptr("base-sha")},
+			Head: &github.PullRequestBranch{
+				Ref:  github.Ptr("main"),
+				SHA:  github.Ptr("fork-head-sha"),
+				Repo: &github.Repository{FullName: github.Ptr("contributor/gh-merge-base-next")},
+			},
+		})
+	})
+
+	client := newTestServerClient(t, mux)
+	base, head, source, err := client.ResolvePullRequest(1234)
+	if err != nil {
+		t.Fatalf("ResolvePullRequest failed: %v", err)
+	}
+	if base != "main" || head != "fork-head-sha" {
+		t.Errorf("Expected main...fork-head-sha, got %s...%s", base, head)
+	}
+	if source.HeadRepository != "contributor/gh-merge-base-next" || source.Author != "contributor" || source.URL == "" {
+		t.Errorf("Unexpected source pull request %+v", source)
+	}
+}
+
+// TestGitBackendResolvePullRequest tests that pull requests cannot be read from a local repository
+func TestGitBackendResolvePullRequest(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("A", "A: Initial commit")
+	client, err := NewLocalClient(context.Background(), f.dir)
+	if err != nil {
+		t.Fatalf("NewLocalClient failed: %v", err)
+	}
+	if _, _, _, err := client.ResolvePullRequest(1); !errors.Is(err, ErrPullRequestsUnsupported) {
+		t.Errorf("Expected ErrPullRequestsUnsupported, got %v", err)
+	}
+}
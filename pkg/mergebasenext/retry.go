@@ -0,0 +1,224 @@
+package mergebasenext
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// ErrRateLimited is matched by errors.Is when a GitHub API request is still rate limited after the retries of the RetryPolicy.
+var ErrRateLimited = errors.New("GitHub API rate limit exceeded")
+
+// maxPeekBodySize is how much of a 403 response body is read to tell a secondary rate limit from a permission error.
+const maxPeekBodySize = 64 * 1024
+
+// RetryPolicy configures how the GitHub backend retries requests that are rate limited.
+// A request is retried after the wait of its Retry-After header, or until X-RateLimit-Reset when the primary rate limit is exhausted,
+// and otherwise after an exponential backoff from InitialBackoff.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried; zero disables retrying.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry when the response does not tell how long to wait, doubled on every retry.
+	InitialBackoff time.Duration
+	// MaxWait is the longest wait before a retry. A rate limit that lifts later fails right away, and backoffs are capped at it.
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of NewClient without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: time.Second,
+	MaxWait:        time.Minute,
+}
+
+// APIError is a failed request of the GitHub backend, telling a rate limit from a missing ref or repository.
+// It matches ErrRateLimited and ErrNotFound with errors.Is, and wraps the go-github error.
+type APIError struct {
+	StatusCode int
+	// RateLimited is true for the primary and the secondary rate limits.
+	RateLimited bool
+	// RetryAt is when the rate limit is expected to lift, zero when it is unknown or the request is not rate limited.
+	RetryAt time.Time
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrRateLimited for a rate limit and ErrNotFound for a 404 response.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.RateLimited
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// newAPIError converts an error of the go-github client into an APIError.
+// Other errors, such as network errors and errors that already are APIErrors, are returned as is.
+func newAPIError(err error) error {
+	var apiErr *APIError
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var errResp *github.ErrorResponse
+	switch {
+	case err == nil, errors.As(err, &apiErr):
+		return err
+	case errors.As(err, &rateLimitErr):
+		return &APIError{StatusCode: responseStatusCode(rateLimitErr.Response), RateLimited: true, RetryAt: rateLimitErr.Rate.Reset.Time, Err: err}
+	case errors.As(err, &abuseErr):
+		apiErr = &APIError{StatusCode: responseStatusCode(abuseErr.Response), RateLimited: true, Err: err}
+		if abuseErr.RetryAfter != nil {
+			apiErr.RetryAt = time.Now().Add(*abuseErr.RetryAfter)
+		}
+		return apiErr
+	case errors.As(err, &errResp):
+		statusCode := responseStatusCode(errResp.Response)
+		return &APIError{StatusCode: statusCode, RateLimited: statusCode == http.StatusTooManyRequests, Err: err}
+	}
+	return err
+}
+
+func responseStatusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// retryTransport retries requests that are rate limited as configured by policy.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	// sleep waits for d, or fails when ctx is done first.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, policy: policy, sleep: sleepContext}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempt := req
+	for retry := 0; ; retry++ {
+		resp, err := t.base.RoundTrip(attempt)
+		if err != nil || retry >= t.policy.MaxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		wait, ok := t.retryWait(resp, retry)
+		if !ok {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		attempt = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+	}
+}
+
+// retryWait returns how long to wait before retrying a rate limited response, and false when resp is not rate limited
+// or the rate limit lifts after the MaxWait of the policy.
+func (t *retryTransport) retryWait(resp *http.Response, retry int) (time.Duration, bool) {
+	if !isRateLimited(resp) {
+		return 0, false
+	}
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, wait <= t.policy.MaxWait
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := max(time.Until(time.Unix(reset, 0)), 0)
+			return wait, wait <= t.policy.MaxWait
+		}
+	}
+	backoff := t.policy.InitialBackoff
+	for range retry {
+		backoff = min(backoff*2, t.policy.MaxWait)
+	}
+	return min(backoff, t.policy.MaxWait), true
+}
+
+// isRateLimited reports a primary or secondary rate limit: a 429 response, or a 403 response that exhausted the primary rate limit,
+// tells when to retry, or links to the documentation of secondary rate limits.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "" {
+			return true
+		}
+		body := peekBody(resp)
+		return bytes.Contains(body, []byte("secondary-rate-limits")) || bytes.Contains(body, []byte("#abuse-rate-limits")) ||
+			strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+	}
+	return false
+}
+
+// peekBody reads the start of the body of resp and puts it back, so that the response can still be read in full.
+func peekBody(resp *http.Response) []byte {
+	if resp.Body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPeekBodySize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
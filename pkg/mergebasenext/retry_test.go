@@ -0,0 +1,205 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// newRetryTestBackend returns a backend retrying with policy whose API requests are served by handler,
+// recording the waits before retries instead of sleeping
+func newRetryTestBackend(t *testing.T, policy RetryPolicy, handler http.Handler) (*GitHubBackend, *[]time.Duration) {
+	waits := &[]time.Duration{}
+	transport := newRetryTransport(newTestServerTransport(t, handler), policy)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	client, err := newGitHubClient("github.com", &http.Client{Transport: transport})
+	if err != nil {
+		t.Fatalf("Failed to create GitHub client: %v", err)
+	}
+	return &GitHubBackend{
+		client: client,
+		repo:   repository.Repository{Host: "github.com", Owner: "srz-zumix", Name: "gh-merge-base-next"},
+	}, waits
+}
+
+// rateLimitedHandler fails the first failures requests with fail, then serves the commit a1
+func rateLimitedHandler(failures int, fail func(w http.ResponseWriter)) (http.Handler, *int) {
+	requests := 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			fail(w)
+			return
+		}
+		_, _ = w.Write([]byte("a1"))
+	}), &requests
+}
+
+func writeSecondaryRateLimit(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit.","documentation_url":"https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+}
+
+// TestRetryRateLimited tests that rate limited requests are retried after the wait the response tells, or with exponential backoff
+func TestRetryRateLimited(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, InitialBackoff: time.Second, MaxWait: time.Minute}
+	testCases := []struct {
+		Name  string
+		Fail  func(w http.ResponseWriter)
+		Count int
+		Waits []time.Duration
+	}{
+		{
+			Name: "Retry-After",
+			Fail: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			Count: 1,
+			Waits: []time.Duration{7 * time.Second},
+		},
+		{
+			Name:  "secondary rate limit without Retry-After",
+			Fail:  writeSecondaryRateLimit,
+			Count: 3,
+			Waits: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			Name: "too many requests",
+			Fail: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			Count: 2,
+			Waits: []time.Duration{time.Second, 2 * time.Second},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			handler, requests := rateLimitedHandler(tc.Count, tc.Fail)
+			backend, waits := newRetryTestBackend(t, policy, handler)
+			sha, err := backend.GetCommitSHA1(context.Background(), "main")
+			if err != nil {
+				t.Fatalf("GetCommitSHA1 failed: %v", err)
+			}
+			if sha != "a1" || *requests != tc.Count+1 {
+				t.Errorf("Expected a1 after %d requests, got %s after %d", tc.Count+1, sha, *requests)
+			}
+			if fmt.Sprint(*waits) != fmt.Sprint(tc.Waits) {
+				t.Errorf("Expected waits %v, got %v", tc.Waits, *waits)
+			}
+		})
+	}
+}
+
+// TestRetryPrimaryRateLimitReset tests waiting until X-RateLimit-Reset, and failing right away when it is beyond MaxWait
+func TestRetryPrimaryRateLimitReset(t *testing.T) {
+	resetIn := 30 * time.Second
+	exhausted := func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(resetIn).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+	}
+
+	handler, _ := rateLimitedHandler(1, exhausted)
+	backend, waits := newRetryTestBackend(t, DefaultRetryPolicy, handler)
+	if _, err := backend.GetCommitSHA1(context.Background(), "main"); err != nil {
+		t.Fatalf("GetCommitSHA1 failed: %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] < resetIn-2*time.Second || (*waits)[0] > resetIn {
+		t.Errorf("Expected to wait about %v, got %v", resetIn, *waits)
+	}
+
+	resetIn = time.Hour
+	handler, requests := rateLimitedHandler(1, exhausted)
+	backend, waits = newRetryTestBackend(t, DefaultRetryPolicy, handler)
+	_, err := backend.GetCommitSHA1(context.Background(), "main")
+	if !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden || time.Until(apiErr.RetryAt) < 59*time.Minute {
+		t.Errorf("Expected a 403 APIError retrying in an hour, got %+v", apiErr)
+	}
+	if *requests != 1 || len(*waits) != 0 {
+		t.Errorf("Expected no retry beyond MaxWait, got %d requests and waits %v", *requests, *waits)
+	}
+}
+
+// TestRetryBudgetExhausted tests that a request still rate limited after MaxRetries fails with ErrRateLimited
+func TestRetryBudgetExhausted(t *testing.T) {
+	handler, requests := rateLimitedHandler(10, writeSecondaryRateLimit)
+	backend, waits := newRetryTestBackend(t, RetryPolicy{MaxRetries: 2, InitialBackoff: time.Second, MaxWait: time.Minute}, handler)
+	_, err := backend.GetCommitSHA1(context.Background(), "main")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if *requests != 3 || len(*waits) != 2 {
+		t.Errorf("Expected 3 requests and 2 waits, got %d requests and waits %v", *requests, *waits)
+	}
+}
+
+// TestNotFoundError tests that a 404 and a permission error fail without retrying, and only the 404 is ErrNotFound
+func TestNotFoundError(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Status   int
+		NotFound bool
+	}{
+		{Name: "not found", Status: http.StatusNotFound, NotFound: true},
+		{Name: "forbidden", Status: http.StatusForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			handler, requests := rateLimitedHandler(1, func(w http.ResponseWriter) {
+				w.WriteHeader(tc.Status)
+				_, _ = w.Write([]byte(`{"message":"failed"}`))
+			})
+			backend, _ := newRetryTestBackend(t, DefaultRetryPolicy, handler)
+			_, err := backend.GetCommitSHA1(context.Background(), "missing")
+			if errors.Is(err, ErrNotFound) != tc.NotFound || errors.Is(err, ErrRateLimited) {
+				t.Errorf("Unexpected error %v", err)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tc.Status {
+				t.Errorf("Expected an APIError with status %d, got %v", tc.Status, err)
+			}
+			if *requests != 1 {
+				t.Errorf("Expected no retry, got %d requests", *requests)
+			}
+		})
+	}
+}
+
+// TestRetryRequestBody tests that a retried request sends its body again
+func TestRetryRequestBody(t *testing.T) {
+	bodies := []string{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"ref":"refs/heads/topic"}`))
+	})
+	backend, _ := newRetryTestBackend(t, DefaultRetryPolicy, handler)
+	if err := backend.CreateBranch(context.Background(), "topic", "a1"); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("Expected the same body twice, got %q", bodies)
+	}
+}
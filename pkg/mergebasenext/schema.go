@@ -0,0 +1,373 @@
+package mergebasenext
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// SchemaVersion is the version of the JSON output schema of Result, PathResult, MergeOutput and PullRequestOutput.
+// It is incremented whenever a field is removed or changes its type or meaning; adding a field keeps the version.
+const SchemaVersion = 1
+
+// Signature is the author or committer of a commit.
+type Signature struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// Login is the GitHub login of the user, empty when the email is not linked to an account or for local repositories.
+	Login string     `json:"login"`
+	Date  *time.Time `json:"date"`
+}
+
+// Commit is the JSON output form of a commit.
+type Commit struct {
+	SHA       string    `json:"sha"`
+	ShortSHA  string    `json:"shortSha"`
+	Message   string    `json:"message"`
+	Author    Signature `json:"author"`
+	Committer Signature `json:"committer"`
+	Parents   []string  `json:"parents"`
+}
+
+// Result is the JSON output of the merge-base-next commit, or of a run of commits for the group and pull request modes.
+// The commit fields are omitted when there is no next commit.
+type Result struct {
+	SchemaVersion int `json:"schemaVersion"`
+	*Commit
+	Depth     int    `json:"depth"`
+	Status    Status `json:"status"`
+	Base      string `json:"base"`
+	Head      string `json:"head"`
+	MergeBase string `json:"mergeBase"`
+	// MergeBaseCommit and MergeBases are only present when the merge-bases are requested.
+	MergeBaseCommit *Commit  `json:"mergeBaseCommit,omitempty"`
+	MergeBases      []string `json:"mergeBases,omitempty"`
+	// From, To, Count and GroupAuthor describe the run of commits merged at once, for MergeBaseNextGroup.
+	// GroupAuthor is the author the run is grouped by, which the author of the last commit need not be with --by-pr.
+	From        string           `json:"from,omitempty"`
+	To          string           `json:"to,omitempty"`
+	Count       int              `json:"count,omitempty"`
+	GroupAuthor string           `json:"groupAuthor,omitempty"`
+	Skipped     []string         `json:"skipped,omitempty"`
+	PullRequest *PullRequestInfo `json:"pullRequest,omitempty"`
+	Files       *FileSummary     `json:"files,omitempty"`
+	Check       *MergeCheck      `json:"check,omitempty"`
+	// SourcePullRequest is the pull request base and head were resolved from, set by the caller of ResolvePullRequest.
+	SourcePullRequest *SourcePullRequest `json:"sourcePullRequest,omitempty"`
+}
+
+// PathResult is the JSON output of the first-parent path from the merge-base-next commit to head.
+type PathResult struct {
+	SchemaVersion int      `json:"schemaVersion"`
+	Commits       []Commit `json:"commits"`
+	Status        Status   `json:"status"`
+	Base          string   `json:"base"`
+	Head          string   `json:"head"`
+	MergeBase     string   `json:"mergeBase"`
+	// MergeBaseCommit and MergeBases are only present when the merge-bases are requested.
+	MergeBaseCommit *Commit  `json:"mergeBaseCommit,omitempty"`
+	MergeBases      []string `json:"mergeBases,omitempty"`
+	// SourcePullRequest is the pull request base and head were resolved from, set by the caller of ResolvePullRequest.
+	SourcePullRequest *SourcePullRequest `json:"sourcePullRequest,omitempty"`
+}
+
+// ResultFields are the JSON fields of Result that can be selected for output.
+var ResultFields = []string{
+	"author",
+	"base",
+	"committer",
+	"count",
+	"depth",
+	"files",
+	"from",
+	"groupAuthor",
+	"head",
+	"mergeBase",
+	"mergeBaseCommit",
+	"mergeBases",
+	"message",
+	"parents",
+	"pullRequest",
+	"schemaVersion",
+	"sha",
+	"shortSha",
+	"skipped",
+	"sourcePullRequest",
+	"status",
+	"to",
+}
+
+// CheckFields are the JSON fields of Result with the merge check, the output of Client.CheckMergeBaseNext, that can be selected for output.
+var CheckFields = []string{
+	"author",
+	"base",
+	"check",
+	"committer",
+	"count",
+	"depth",
+	"files",
+	"from",
+	"groupAuthor",
+	"head",
+	"mergeBase",
+	"mergeBaseCommit",
+	"mergeBases",
+	"message",
+	"parents",
+	"pullRequest",
+	"schemaVersion",
+	"sha",
+	"shortSha",
+	"skipped",
+	"sourcePullRequest",
+	"status",
+	"to",
+}
+
+// PathFields are the JSON fields of PathResult that can be selected for output.
+var PathFields = []string{
+	"base",
+	"commits",
+	"head",
+	"mergeBase",
+	"mergeBaseCommit",
+	"mergeBases",
+	"schemaVersion",
+	"sourcePullRequest",
+	"status",
+}
+
+// MergeOutput is the JSON output of Client.Merge.
+type MergeOutput struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Method        MergeMethod `json:"method"`
+	DryRun        bool        `json:"dryRun"`
+	Base          string      `json:"base"`
+	Head          string      `json:"head"`
+	// Next is the next commit merged, or would be merged in a dry run, in the form of the default output.
+	Next        *Result          `json:"next"`
+	Message     string           `json:"message,omitempty"`
+	MergeCommit *Commit          `json:"mergeCommit,omitempty"`
+	Branch      string           `json:"branch,omitempty"`
+	PullRequest *PullRequestInfo `json:"pullRequest,omitempty"`
+	// SourcePullRequest is the pull request base and head were resolved from, set by the caller of ResolvePullRequest.
+	SourcePullRequest *SourcePullRequest `json:"sourcePullRequest,omitempty"`
+}
+
+// PullRequestOutput is the JSON output of Client.OpenPullRequest.
+type PullRequestOutput struct {
+	MergeOutput
+	Reviewers     []string `json:"reviewers"`
+	TeamReviewers []string `json:"teamReviewers"`
+	Assignees     []string `json:"assignees"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+// MergeFields are the JSON fields of MergeOutput that can be selected for output.
+var MergeFields = []string{
+	"base",
+	"branch",
+	"dryRun",
+	"head",
+	"mergeCommit",
+	"message",
+	"method",
+	"next",
+	"pullRequest",
+	"schemaVersion",
+	"sourcePullRequest",
+}
+
+// PullRequestFields are the JSON fields of PullRequestOutput that can be selected for output.
+var PullRequestFields = []string{
+	"assignees",
+	"base",
+	"branch",
+	"dryRun",
+	"head",
+	"mergeCommit",
+	"message",
+	"method",
+	"next",
+	"pullRequest",
+	"reviewers",
+	"schemaVersion",
+	"sourcePullRequest",
+	"teamReviewers",
+	"warnings",
+}
+
+// NewCommit converts a commit returned by a backend into its JSON output form.
+func NewCommit(commit *github.RepositoryCommit) *Commit {
+	parents := []string{}
+	for _, parent := range commit.Parents {
+		parents = append(parents, parent.GetSHA())
+	}
+	sha := commit.GetSHA()
+	return &Commit{
+		SHA:       sha,
+		ShortSHA:  sha[:min(len(sha), 7)],
+		Message:   commit.GetCommit().GetMessage(),
+		Author:    newSignature(commit.GetCommit().GetAuthor(), commit.GetAuthor()),
+		Committer: newSignature(commit.GetCommit().GetCommitter(), commit.GetCommitter()),
+		Parents:   parents,
+	}
+}
+
+func newSignature(author *github.CommitAuthor, user *github.User) Signature {
+	signature := Signature{
+		Name:  author.GetName(),
+		Email: author.GetEmail(),
+		Login: user.GetLogin(),
+	}
+	if author.GetDate().Time.IsZero() {
+		return signature
+	}
+	date := author.GetDate().Time
+	signature.Date = &date
+	return signature
+}
+
+// NewResult converts a MergeBaseNext into its JSON output form.
+func NewResult(next *MergeBaseNext) *Result {
+	result := &Result{
+		SchemaVersion: SchemaVersion,
+		Depth:         next.Depth,
+		Status:        next.Status,
+		Base:          next.Base,
+		Head:          next.Head,
+		MergeBase:     next.MergeBase,
+		MergeBases:    next.MergeBases,
+		Skipped:       next.Skipped,
+		PullRequest:   next.PullRequest,
+		Files:         next.Files,
+		Check:         next.Check,
+	}
+	if next.Commit != nil {
+		result.Commit = NewCommit(next.Commit)
+	}
+	if next.MergeBaseCommit != nil {
+		result.MergeBaseCommit = NewCommit(next.MergeBaseCommit)
+	}
+	return result
+}
+
+// NewGroupResult converts a MergeBaseNextGroup into its JSON output form, where the commit is the last commit of the run
+// and groupAuthor is the author of the run.
+func NewGroupResult(group *MergeBaseNextGroup) *Result {
+	result := &Result{
+		SchemaVersion: SchemaVersion,
+		Depth:         group.Depth,
+		Status:        group.Status,
+		Base:          group.Base,
+		Head:          group.Head,
+		MergeBase:     group.MergeBase,
+		MergeBases:    group.MergeBases,
+		From:          group.From,
+		To:            group.To,
+		Count:         group.Count,
+		GroupAuthor:   group.Author,
+		Skipped:       group.Skipped,
+		PullRequest:   group.PullRequest,
+		Files:         group.Files,
+	}
+	if group.Commit != nil {
+		result.Commit = NewCommit(group.Commit)
+	}
+	if group.MergeBaseCommit != nil {
+		result.MergeBaseCommit = NewCommit(group.MergeBaseCommit)
+	}
+	return result
+}
+
+// NewPathResult converts a MergeBasePath into its JSON output form.
+func NewPathResult(path *MergeBasePath) *PathResult {
+	commits := []Commit{}
+	for _, commit := range path.Commits {
+		commits = append(commits, *NewCommit(commit))
+	}
+	result := &PathResult{
+		SchemaVersion: SchemaVersion,
+		Commits:       commits,
+		Status:        path.Status,
+		Base:          path.Base,
+		Head:          path.Head,
+		MergeBase:     path.MergeBase,
+		MergeBases:    path.MergeBases,
+	}
+	if path.MergeBaseCommit != nil {
+		result.MergeBaseCommit = NewCommit(path.MergeBaseCommit)
+	}
+	return result
+}
+
+// NewMergeOutput converts a MergeResult into its JSON output form.
+func NewMergeOutput(merge *MergeResult) *MergeOutput {
+	output := &MergeOutput{
+		SchemaVersion: SchemaVersion,
+		Method:        merge.Method,
+		DryRun:        merge.DryRun,
+		Base:          merge.Base,
+		Head:          merge.Head,
+		Next:          NewResult(merge.Next),
+		Message:       merge.Message,
+		Branch:        merge.Branch,
+	}
+	if merge.MergeCommit != nil {
+		output.MergeCommit = NewCommit(merge.MergeCommit)
+	}
+	if merge.PullRequest != nil {
+		output.PullRequest = newPullRequestInfo(merge.PullRequest)
+	}
+	return output
+}
+
+// NewPullRequestOutput converts a PullRequestResult into its JSON output form.
+func NewPullRequestOutput(result *PullRequestResult) *PullRequestOutput {
+	return &PullRequestOutput{
+		MergeOutput:   *NewMergeOutput(&result.MergeResult),
+		Reviewers:     result.Reviewers,
+		TeamReviewers: result.TeamReviewers,
+		Assignees:     result.Assignees,
+		Warnings:      result.Warnings,
+	}
+}
+
+// ExportData returns the selected fields of the result for the gh --json flag.
+func (r *Result) ExportData(fields []string) map[string]any {
+	return exportFields(r, fields)
+}
+
+// ExportData returns the selected fields of the result for the gh --json flag.
+func (r *PathResult) ExportData(fields []string) map[string]any {
+	return exportFields(r, fields)
+}
+
+// ExportData returns the selected fields of the output for the gh --json flag.
+func (o *MergeOutput) ExportData(fields []string) map[string]any {
+	return exportFields(o, fields)
+}
+
+// ExportData returns the selected fields of the output for the gh --json flag.
+func (o *PullRequestOutput) ExportData(fields []string) map[string]any {
+	return exportFields(o, fields)
+}
+
+// exportFields picks fields from the JSON encoding of v, so that the selected fields keep the documented schema.
+// Fields v does not have, such as the commit fields when there is no next commit, are null.
+func exportFields(v any, fields []string) map[string]any {
+	data := make(map[string]any, len(fields))
+	all := map[string]json.RawMessage{}
+	if encoded, err := json.Marshal(v); err == nil {
+		_ = json.Unmarshal(encoded, &all)
+	}
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			data[field] = value
+		} else {
+			data[field] = nil
+		}
+	}
+	return data
+}
@@ -0,0 +1,162 @@
+package mergebasenext
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// updateGolden rewrites the golden files with the current output: go test -run TestSchemaGolden -update
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// schemaGraph has a merge commit and a commit without a login on the first-parent path to head
+var schemaGraph = `
+main    -> b1
+feature -> c2
+empty   -> a1
+
+c2 <- c1 p1 @alice #11   : Merge pull request #11 from example/topic
+p1 <- a1 @alice #11      : P1: Work in pull request 11
+c1 <- a1                 : C1: Feature change
+b1 <- a1                 : B: Main branch development
+a1                       : A: Initial commit (merge-base)
+`
+
+// assertGolden compares the indented JSON encoding of v with testdata/golden/<name>.json
+func assertGolden(t *testing.T, name string, v any) {
+	t.Helper()
+	actual, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", name, err)
+	}
+	actual = append(actual, '\n')
+	golden := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(golden), err)
+		}
+		if err := os.WriteFile(golden, actual, 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", golden, err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run with -update to create it): %v", golden, err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("Output of %s does not match %s:\n%s", name, golden, actual)
+	}
+}
+
+// TestSchemaGolden pins the JSON output schema of every result type
+func TestSchemaGolden(t *testing.T) {
+	backend, err := NewMemoryBackend(schemaGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	client := NewClientWithBackend(context.Background(), backend, WithPullRequests())
+
+	t.Run("next", func(t *testing.T) {
+		next, err := client.GetMergeBaseNext("main", "feature", 1)
+		if err != nil {
+			t.Fatalf("GetMergeBaseNext failed: %v", err)
+		}
+		assertGolden(t, "next", NewResult(next))
+	})
+	t.Run("next-up-to-date", func(t *testing.T) {
+		next, err := client.GetMergeBaseNext("main", "empty", 1)
+		if err != nil {
+			t.Fatalf("GetMergeBaseNext failed: %v", err)
+		}
+		assertGolden(t, "next-up-to-date", NewResult(next))
+	})
+	t.Run("path", func(t *testing.T) {
+		path, err := client.GetMergeBasePath("main", "feature")
+		if err != nil {
+			t.Fatalf("GetMergeBasePath failed: %v", err)
+		}
+		assertGolden(t, "path", NewPathResult(path))
+	})
+	t.Run("by-pr", func(t *testing.T) {
+		group, err := client.GetMergeBaseNextByPullRequest("main", "feature", 2)
+		if err != nil {
+			t.Fatalf("GetMergeBaseNextByPullRequest failed: %v", err)
+		}
+		assertGolden(t, "by-pr", NewGroupResult(group))
+	})
+	t.Run("group", func(t *testing.T) {
+		group, err := NewClientWithBackend(context.Background(), backend, WithFiles()).GetMergeBaseNextGroup("main", "feature", 2)
+		if err != nil {
+			t.Fatalf("GetMergeBaseNextGroup failed: %v", err)
+		}
+		assertGolden(t, "group", NewGroupResult(group))
+	})
+	t.Run("next-merge-bases", func(t *testing.T) {
+		backend, err := NewMemoryBackend(crissCrossGraph)
+		if err != nil {
+			t.Fatalf("NewMemoryBackend failed: %v", err)
+		}
+		next, err := NewClientWithBackend(context.Background(), backend, WithMergeBases()).GetMergeBaseNext("main", "feature", 1)
+		if err != nil {
+			t.Fatalf("GetMergeBaseNext failed: %v", err)
+		}
+		assertGolden(t, "next-merge-bases", NewResult(next))
+	})
+	t.Run("merge", func(t *testing.T) {
+		merge, err := newGraphClient(t, schemaGraph).Merge("main", "feature", MergeOptions{Method: MergeMethodMerge})
+		if err != nil {
+			t.Fatalf("Merge failed: %v", err)
+		}
+		assertGolden(t, "merge", NewMergeOutput(merge))
+	})
+	t.Run("pull-request", func(t *testing.T) {
+		result, err := newGraphClient(t, schemaGraph).OpenPullRequest("main", "feature", PullRequestOptions{Steps: 2, Assign: true})
+		if err != nil {
+			t.Fatalf("OpenPullRequest failed: %v", err)
+		}
+		assertGolden(t, "pull-request", NewPullRequestOutput(result))
+	})
+	t.Run("commit", func(t *testing.T) {
+		authored := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		committed := time.Date(2025, 1, 3, 4, 5, 6, 0, time.FixedZone("JST", 9*60*60))
+		commit := &github.RepositoryCommit{
+			SHA: github.Ptr("0123456789abcdef0123456789abcdef01234567"),
+			Commit: &github.Commit{
+				Message:   github.Ptr("Add the feature\n\nWith a body."),
+				Author:    &github.CommitAuthor{Name: github.Ptr("Octo Cat"), Email: github.Ptr("octocat@example.com"), Date: &github.Timestamp{Time: authored}},
+				Committer: &github.CommitAuthor{Name: github.Ptr("GitHub"), Email: github.Ptr("noreply@github.com"), Date: &github.Timestamp{Time: committed}},
+			},
+			Author: &github.User{Login: github.Ptr("octocat")},
+			Parents: []*github.Commit{
+				{SHA: github.Ptr("89abcdef0123456789abcdef0123456789abcdef")},
+			},
+		}
+		assertGolden(t, "commit", NewCommit(commit))
+	})
+}
+
+// TestExportData tests selecting fields for the --json flag, where fields the result lacks are null
+func TestExportData(t *testing.T) {
+	client := newGraphClient(t, schemaGraph)
+	next, err := client.GetMergeBaseNext("main", "empty", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	data := NewResult(next).ExportData([]string{"sha", "status", "schemaVersion"})
+	actual, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Failed to encode exported data: %v", err)
+	}
+	expected := `{"schemaVersion":1,"sha":null,"status":"up-to-date"}`
+	if string(actual) != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
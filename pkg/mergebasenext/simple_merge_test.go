@@ -23,6 +23,17 @@ import (
 	"testing"
 )
 
+// simpleMergeGraph describes the commit graph above for the offline test backend
+var simpleMergeGraph = `
+testdata/simple-merge/main    -> 0eb5947
+testdata/simple-merge/feature -> 904d00b
+
+904d00b                                  <- d761e77 : D: Feature development 2
+d761e77fe7bbc5fd65e8ee14b8a65ea2ff1f0043 <- cdddb51 : C: Feature development 1
+0eb59474ced5e6cd338c9ef1406acb4b4522d9fc <- cdddb51 : B: Main branch development
+cdddb51                                             : A: Initial commit (merge-base)
+`
+
 // TestSimpleMerge tests the simple merge scenarios with commit hash and depth validation
 func TestSimpleMerge(t *testing.T) {
 	testCases := []TestCase{
@@ -43,12 +54,13 @@ func TestSimpleMerge(t *testing.T) {
 			Desc:  "Find next commit from merge-base (A: Initial commit) to feature branch",
 		},
 		{
-			Name:  "AutoDetectMergeBaseWalkToHead",
-			Base:  "testdata/simple-merge/main",
-			Head:  "testdata/simple-merge/feature",
-			SHA:   "d761e77fe7bbc5fd65e8ee14b8a65ea2ff1f0043",
-			Depth: 2,
-			Desc:  "Auto-detect merge-base between main and feature, walk to feature",
+			Name:   "AutoDetectMergeBaseWalkToHead",
+			Base:   "testdata/simple-merge/main",
+			Head:   "testdata/simple-merge/feature",
+			SHA:    "d761e77fe7bbc5fd65e8ee14b8a65ea2ff1f0043",
+			Depth:  2,
+			Status: StatusDiverged,
+			Desc:   "Auto-detect merge-base between main and feature, walk to feature",
 		},
 		{
 			Name:  "AutoDetectMergeBaseWalkToBase",
@@ -0,0 +1,137 @@
+package mergebasenext
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// SkipMarker marks a commit message whose commit should be folded into the next merge instead of merged individually.
+const SkipMarker = "[skip-merge]"
+
+// SkipFilter selects commits GetMergeBaseNext advances past, such as version bumps or bot commits.
+// A commit is skipped when it matches any of the criteria.
+type SkipFilter struct {
+	// Authors and Committers match the GitHub login, name or email of the commit author or committer, ignoring case.
+	Authors    []string
+	Committers []string
+	// Message matches the commit message.
+	Message *regexp.Regexp
+	// Paths are glob patterns; a commit matches when every file it changes matches one of them.
+	// A pattern also matches the files below a matching directory.
+	Paths []string
+	// Marked matches commits whose message contains SkipMarker.
+	Marked bool
+}
+
+// IsZero reports whether the filter matches no commit.
+func (f SkipFilter) IsZero() bool {
+	return len(f.Authors) == 0 && len(f.Committers) == 0 && f.Message == nil && len(f.Paths) == 0 && !f.Marked
+}
+
+// Validate reports a malformed path pattern.
+func (f SkipFilter) Validate() error {
+	for _, pattern := range f.Paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// selectNext returns the index in path of the commit steps commits past the merge-base, clamped at head,
+// and the commits skipped on the way. Skipped commits do not count as steps, and head is never skipped.
+func (c *Client) selectNext(path []*github.RepositoryCommit, steps int) (int, []string, error) {
+	if c.skipFilter == nil {
+		return min(max(steps, 1), len(path)) - 1, nil, nil
+	}
+	remaining := max(steps, 1)
+	skipped := []string{}
+	for i, commit := range path {
+		if i == len(path)-1 {
+			return i, skipped, nil
+		}
+		skip, err := c.matchSkipFilter(commit)
+		if err != nil {
+			return 0, nil, err
+		}
+		if skip {
+			skipped = append(skipped, commit.GetSHA())
+			continue
+		}
+		remaining--
+		if remaining == 0 {
+			return i, skipped, nil
+		}
+	}
+	return len(path) - 1, skipped, nil
+}
+
+func (c *Client) matchSkipFilter(commit *github.RepositoryCommit) (bool, error) {
+	f := c.skipFilter
+	message := commit.GetCommit().GetMessage()
+	if f.Marked && strings.Contains(message, SkipMarker) {
+		return true, nil
+	}
+	if f.Message != nil && f.Message.MatchString(message) {
+		return true, nil
+	}
+	if matchIdentity(f.Authors, commit.GetAuthor(), commit.GetCommit().GetAuthor()) {
+		return true, nil
+	}
+	if matchIdentity(f.Committers, commit.GetCommitter(), commit.GetCommit().GetCommitter()) {
+		return true, nil
+	}
+	if len(f.Paths) == 0 {
+		return false, nil
+	}
+	files, err := c.commitFiles(commit)
+	if err != nil {
+		return false, err
+	}
+	return len(files) > 0 && !slices.ContainsFunc(files, func(file *github.CommitFile) bool { return !matchPaths(f.Paths, file.GetFilename()) }), nil
+}
+
+// commitFiles returns the files changed by commit, asking the backend when the commit does not list them.
+func (c *Client) commitFiles(commit *github.RepositoryCommit) ([]*github.CommitFile, error) {
+	if commit.Files != nil {
+		return commit.Files, nil
+	}
+	lister, ok := c.backend.(FileLister)
+	if !ok {
+		return nil, fmt.Errorf("backend does not list the files of %s", commit.GetSHA())
+	}
+	files, err := lister.ListCommitFiles(c.ctx, commit.GetSHA())
+	if err != nil {
+		return nil, fmt.Errorf("error listing files of %s: %w", commit.GetSHA(), err)
+	}
+	return files, nil
+}
+
+func matchIdentity(identities []string, user *github.User, author *github.CommitAuthor) bool {
+	for _, identity := range identities {
+		for _, value := range []string{user.GetLogin(), author.GetName(), author.GetEmail()} {
+			if value != "" && strings.EqualFold(value, identity) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPaths reports whether file, or a directory containing it, matches one of the glob patterns.
+func matchPaths(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		for name := file; name != "." && name != "/"; name = path.Dir(name) {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package mergebasenext
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// skipGraph has bot and version bump commits between the feature commits
+var skipGraph = `
+main    -> b1
+feature -> c5
+
+c5 <- c4 @octocat +src/b.go                : C5: Second feature change
+c4 <- c3 @octocat +VERSION                 : C4: Bump version [skip-merge]
+c3 <- c2 @octocat +src/a.go                : C3: First feature change
+c2 <- c1 @renovate[bot] +go.mod +go.sum    : C2: Update dependencies
+c1 <- a1 @octocat +VERSION +docs/CHANGES.md : C1: Bump version to 1.1
+b1 <- a1 @hubot                            : B: Main branch development
+a1                                         : A: Initial commit (merge-base)
+`
+
+// TestSkipFilter tests that GetMergeBaseNext advances past commits matching the skip filter
+func TestSkipFilter(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Filter  SkipFilter
+		Steps   int
+		SHA     string
+		Depth   int
+		Skipped []string
+	}{
+		{Name: "NoFilter", Steps: 1, SHA: "c1", Depth: 5, Skipped: nil},
+		{Name: "Author", Filter: SkipFilter{Authors: []string{"renovate[bot]"}}, Steps: 2, SHA: "c3", Depth: 3, Skipped: []string{"c2"}},
+		{Name: "Message", Filter: SkipFilter{Message: regexp.MustCompile(`^C1: Bump version`)}, Steps: 1, SHA: "c2", Depth: 4, Skipped: []string{"c1"}},
+		{Name: "Paths", Filter: SkipFilter{Paths: []string{"VERSION", "docs/", "go.*"}}, Steps: 1, SHA: "c3", Depth: 3, Skipped: []string{"c1", "c2"}},
+		{Name: "Marked", Filter: SkipFilter{Marked: true}, Steps: 4, SHA: "c5", Depth: 1, Skipped: []string{"c4"}},
+		{Name: "StepsCountUnskipped", Filter: SkipFilter{Paths: []string{"VERSION", "docs", "go.*"}}, Steps: 2, SHA: "c5", Depth: 1, Skipped: []string{"c1", "c2", "c4"}},
+		{Name: "HeadIsNeverSkipped", Filter: SkipFilter{Authors: []string{"OCTOCAT"}}, Steps: 2, SHA: "c5", Depth: 1, Skipped: []string{"c1", "c3", "c4"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			backend, err := NewMemoryBackend(skipGraph)
+			if err != nil {
+				t.Fatalf("NewMemoryBackend failed: %v", err)
+			}
+			client := NewClientWithBackend(context.Background(), backend, WithSkipFilter(tc.Filter))
+			result, err := client.GetMergeBaseNext("main", "feature", tc.Steps)
+			if err != nil {
+				t.Fatalf("GetMergeBaseNext failed: %v", err)
+			}
+			if result.SHA != tc.SHA || result.Depth != tc.Depth {
+				t.Errorf("Expected %s at depth %d, got %s at depth %d", tc.SHA, tc.Depth, result.SHA, result.Depth)
+			}
+			if !slices.Equal(result.Skipped, tc.Skipped) {
+				t.Errorf("Expected skipped %v, got %v", tc.Skipped, result.Skipped)
+			}
+		})
+	}
+}
+
+// TestSkipFilterCommitter tests matching committers by login, name or email
+func TestSkipFilterCommitter(t *testing.T) {
+	commit := &github.RepositoryCommit{
+		SHA:       github.Ptr("c1"),
+		Committer: &github.User{Login: github.Ptr("web-flow")},
+		Commit: &github.Commit{
+			Committer: &github.CommitAuthor{Name: github.Ptr("GitHub"), Email: github.Ptr("noreply@github.com")},
+		},
+	}
+	for _, committer := range []string{"web-flow", "github", "noreply@github.com"} {
+		client := NewClientWithBackend(context.Background(), nil, WithSkipFilter(SkipFilter{Committers: []string{committer}}))
+		if skip, err := client.matchSkipFilter(commit); err != nil || !skip {
+			t.Errorf("Expected committer %s to match, got %v (%v)", committer, skip, err)
+		}
+	}
+}
+
+// TestSkipFilterValidate tests that malformed path patterns are rejected
+func TestSkipFilterValidate(t *testing.T) {
+	if err := (SkipFilter{Paths: []string{"docs/[a-"}}).Validate(); err == nil {
+		t.Errorf("Expected error but got none")
+	}
+	if err := (SkipFilter{Paths: []string{"docs/*.md"}}).Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestSkipFilterGitBackend tests path filters with the files listed by the local git backend
+func TestSkipFilterGitBackend(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("A", "A: Initial commit")
+	f.git("checkout", "--quiet", "-b", "feature")
+	f.write("VERSION", "1.1\n")
+	f.write("docs/CHANGES.md", "1.1\n")
+	f.commit("C1", "C1: Bump version")
+	f.write("src/a.go", "package a\n")
+	f.commit("C2", "C2: Feature change")
+
+	backend, err := NewGitBackend(f.dir)
+	if err != nil {
+		t.Fatalf("NewGitBackend failed: %v", err)
+	}
+	client := NewClientWithBackend(context.Background(), backend, WithSkipFilter(SkipFilter{Paths: []string{"VERSION", "docs"}}))
+	result, err := client.GetMergeBaseNext("main", "feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.SHA != f.sha("C2") || !slices.Equal(result.Skipped, []string{f.sha("C1")}) {
+		t.Errorf("Expected C2 with C1 skipped, got %s with %v skipped", result.SHA, result.Skipped)
+	}
+}
@@ -0,0 +1,76 @@
+package mergebasenext
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// TestWalkCycleDetection tests that a first-parent pointer looping back is reported instead of walked forever
+func TestWalkCycleDetection(t *testing.T) {
+	graph, err := NewMemoryBackend(`
+c3 <- c2 : C
+c2 <- c1 : B
+c1 <- c3 : A (parent loops back to C)
+`)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+	head, _ := graph.Commit("c3")
+	_, err = walkToMainline(graph, head, 0, 0)
+	if !errors.Is(err, ErrCycleDetected) {
+		t.Errorf("Expected ErrCycleDetected, got %v", err)
+	}
+}
+
+// TestWalkMaxDepth tests the maximum depth guard of the first-parent walk
+func TestWalkMaxDepth(t *testing.T) {
+	backend, err := NewMemoryBackend(fastForwardGraph)
+	if err != nil {
+		t.Fatalf("NewMemoryBackend failed: %v", err)
+	}
+
+	client := NewClientWithBackend(context.Background(), backend, WithMaxDepth(2))
+	_, err = client.GetMergeBaseNext("testdata/fast-forward/main", "testdata/fast-forward/feature", 1)
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected ErrMaxDepthExceeded, got %v", err)
+	}
+
+	client = NewClientWithBackend(context.Background(), backend, WithMaxDepth(3))
+	result, err := client.GetMergeBaseNext("testdata/fast-forward/main", "testdata/fast-forward/feature", 1)
+	if err != nil {
+		t.Fatalf("GetMergeBaseNext failed: %v", err)
+	}
+	if result.Depth != 3 {
+		t.Errorf("Expected Depth 3, got %d", result.Depth)
+	}
+}
+
+// TestWalkLongComparison tests walking a comparison far larger than the compare API page size through the SHA index
+func TestWalkLongComparison(t *testing.T) {
+	n := 20000
+	comparison := &github.CommitsComparison{}
+	for i := 1; i <= n; i++ {
+		comparison.Commits = append(comparison.Commits, &github.RepositoryCommit{
+			SHA:     github.Ptr(longHistorySHA(i)),
+			Parents: []*github.Commit{{SHA: github.Ptr(longHistorySHA(i - 1))}},
+		})
+	}
+	graph := newComparisonGraph(comparison)
+	head, ok := graph.Commit(longHistorySHA(n))
+	if !ok {
+		t.Fatalf("Head commit %s not found in graph", longHistorySHA(n))
+	}
+	path, err := walkToMainline(graph, head, 0, 0)
+	if err != nil {
+		t.Fatalf("walkToMainline failed: %v", err)
+	}
+	if len(path) != n {
+		t.Errorf("Expected %d commits, got %d", n, len(path))
+	}
+	if _, ok := graph.Commit(longHistorySHA(0)); ok {
+		t.Errorf("Expected merge-base %s to be outside the graph", longHistorySHA(0))
+	}
+}